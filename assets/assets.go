@@ -0,0 +1,17 @@
+// Package assets carries runtime files that ship inside the binary.
+package assets
+
+import "embed"
+
+// Prompts are the AI prompt templates. Set ai.prompts_dir to edit them on
+// disk without rebuilding.
+//
+//go:embed prompts/*.tmpl
+var Prompts embed.FS
+
+// Redoc holds the vendored docs page script (redoc/redoc.standalone.js),
+// fetched by fetchRedoc.sh.
+//
+//go:generate sh fetchRedoc.sh
+//go:embed all:redoc
+var Redoc embed.FS
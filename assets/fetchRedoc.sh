@@ -0,0 +1,8 @@
+#!/bin/sh
+# Vendors the Redoc bundle that /v1/docs serves, so the docs page needs no
+# CDN at runtime. Run via `go generate ./assets` and commit the result.
+set -e
+REDOC_VERSION=2.1.5
+cd "$(dirname "$0")"
+curl -fsSL "https://cdn.redoc.ly/redoc/v${REDOC_VERSION}/bundles/redoc.standalone.js" -o redoc/redoc.standalone.js
+echo "✅ redoc ${REDOC_VERSION} saved to assets/redoc/redoc.standalone.js"
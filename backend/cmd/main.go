@@ -3,11 +3,21 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/fplimporter"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/network"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	v1 "bitbucket.org/Local/fpl-assistant/backend/internal/network/v1"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/scheduler"
 	"github.com/joho/godotenv"
 )
 
@@ -17,18 +27,80 @@ func main() {
 		log.Printf("⚠️ Could not load .env file: %v", err)
 	}
 
-	// Create application context
-	ctx := context.Background()
+	// Application context, cancelled on Ctrl+C / SIGTERM so the server
+	// drains and running imports stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	// Load configuration
-	cfg := config.LoadConfig()
-	// Initialize database
-	repository.InitDB(ctx, &cfg.Database)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(&cfg.Database, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(ctx, cfg, os.Args[2:])
+		return
+	}
+
+	// Initialize database and repositories
+	db := repository.InitDB(ctx, &cfg.Database)
+	store := repository.NewGormStore(db)
+	// Importer (payload cache) and background jobs
+	importer := fplimporter.New(store, &cfg.Importer)
+	jobManager := jobs.NewManager(ctx)
 
 	// Set up router and start server
-	router := network.NewRouter()
+	provider := ai.NewOrDisabled(&cfg.AI)
+	templates, err := prompts.Load(cfg.AI.PromptsDir)
+	if err != nil {
+		log.Fatalf("❌ Prompt templates: %v", err)
+	}
+	builder := assistant.NewBuilder(store, templates)
+	meter := assistant.NewMeter(store, provider.Name(), cfg.AI.DailyQuota, cfg.AI.CacheTTL, cfg.AI.CacheEntries)
+	// Write the next gameweek's preview once an import brings its fixtures
+	reporter := assistant.NewReporter(store, provider, builder, meter)
+	importer.AfterImport(func() {
+		if err := reporter.QueueReport(jobManager, "import"); err != nil {
+			log.Printf("⚠️ Failed to queue gameweek report: %v", err)
+		}
+	})
+	// FPL sync, started once the import hooks are in place
+	syncer := scheduler.Start(ctx, &cfg.Sync, importer, jobManager, store.Fixtures)
+
+	router := network.NewRouter(&cfg.Server, v1.Deps{
+		Store:     store,
+		Importer:  importer,
+		Jobs:      jobManager,
+		Scheduler: syncer,
+		Auth:      auth.NewKeyStore(cfg.Auth.APIKeys),
+		Sessions:  auth.NewSessions(store, &cfg.Auth, cfg.Server.TLS()),
+		AI:        provider,
+		Assistant: builder,
+		Chat:      assistant.NewChat(store, provider, builder, cfg.AI.HistoryTokens),
+		Tools:     assistant.NewTools(store, cfg.AI.MaxToolSteps, cfg.AIDeadline()),
+		Advisor:   assistant.NewAdvisor(store, provider, builder, cfg.AIDeadline()),
+		Meter:     meter,
+		Guard:     assistant.NewInputGuard(cfg.AI.MaxInputChars),
+		Reporter:  reporter,
+		Shutdown:  ctx,
 
-	log.Println("🚀 Server running at http://localhost:8080")
-	if err := http.ListenAndServe(":8080", router); err != nil {
+		AIWriteTimeout: cfg.AIWriteTimeout(),
+	})
+
+	if err := network.Serve(ctx, &cfg.Server, router); err != nil {
 		log.Fatalf("❌ Server crashed: %v", err)
 	}
+
+	// Give cancelled imports a moment to unwind before exiting
+	waitCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := jobManager.Wait(waitCtx); err != nil {
+		log.Printf("⚠️ Jobs still running at exit: %v", err)
+	}
+	log.Println("👋 Server stopped")
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+  up             apply all pending migrations
+  down [n|all]   roll back n migrations (default 1) or all of them
+  status         print current and expected schema version
+  force <v>      mark version v as applied and clear the dirty flag`
+
+// runMigrate implements the `migrate` subcommand.
+func runMigrate(cfg *config.DatabaseConfig, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "up":
+		err = repository.MigrateUp(cfg)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = 0
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("❌ invalid step count %q", args[1])
+			}
+		}
+		err = repository.MigrateDown(cfg, steps)
+	case "force":
+		if len(args) < 2 {
+			log.Fatal("❌ force needs a version")
+		}
+		v, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			log.Fatalf("❌ invalid version %q", args[1])
+		}
+		err = repository.MigrateForce(cfg, v)
+	case "status":
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	st, err := repository.GetMigrationStatus(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	log.Printf("✅ %s database: %s", cfg.Type, st)
+}
@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fake answers without any network call. Replies depend only on the
+// request, so it suits local development, demos and handler tests.
+type Fake struct{}
+
+func (Fake) Name() string { return "fake" }
+
+func (Fake) Generate(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	var last string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == RoleUser {
+			last = req.Messages[i].Content
+			break
+		}
+	}
+	text := fmt.Sprintf("🤖 (fake AI) You asked: %q. Configure [ai] provider for real answers.", strings.TrimSpace(last))
+	if req.Schema != nil {
+		// an empty transfer suggestion: no moves, just a note
+		b, _ := json.Marshal(map[string]any{"transfers": []any{}, "reasoning": text})
+		text = string(b)
+	}
+	return Response{
+		Text:  text,
+		Model: "fake",
+		Usage: Usage{PromptTokens: len(strings.Fields(req.System + " " + last)), CompletionTokens: len(strings.Fields(text))},
+	}, nil
+}
+
+// Stream hands out the Generate reply a word at a time.
+func (f Fake) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (Response, error) {
+	resp, err := f.Generate(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	words := strings.SplitAfter(resp.Text, " ")
+	for _, w := range words {
+		if err := ctx.Err(); err != nil {
+			return Response{}, err
+		}
+		if err := onDelta(w); err != nil {
+			return Response{}, err
+		}
+	}
+	return resp, nil
+}
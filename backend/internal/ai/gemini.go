@@ -1,84 +1,294 @@
-// ai/gemini.go
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"time"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
 )
 
+const geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
 type genPart struct {
-	Text string `json:"text"`
+	Text             string               `json:"text,omitempty"`
+	Thought          bool                 `json:"thought,omitempty"` // reasoning summary, not part of the answer
+	FunctionCall     *genFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *genFunctionResponse `json:"functionResponse,omitempty"`
+}
+type genFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+type genFunctionResponse struct {
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+type genFunctionDecl struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+type genTool struct {
+	FunctionDeclarations []genFunctionDecl `json:"functionDeclarations"`
 }
 type genContent struct {
-	Role  string    `json:"role"`
+	Role  string    `json:"role,omitempty"`
 	Parts []genPart `json:"parts"`
 }
+type genConfig struct {
+	Temperature      float64        `json:"temperature"`
+	MaxOutputTokens  int            `json:"maxOutputTokens"`
+	ResponseMimeType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
+}
 type genReq struct {
-	Contents []genContent `json:"contents"`
+	SystemInstruction *genContent  `json:"systemInstruction,omitempty"`
+	Contents          []genContent `json:"contents"`
+	Tools             []genTool    `json:"tools,omitempty"`
+	GenerationConfig  genConfig    `json:"generationConfig"`
+}
+type genSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked"`
+}
+type genCandidate struct {
+	Content struct {
+		Parts []genPart `json:"parts"`
+	} `json:"content"`
+	FinishReason  string            `json:"finishReason"`
+	SafetyRatings []genSafetyRating `json:"safetyRatings"`
 }
 type genResp struct {
-	Candidates []struct {
-		Content struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		} `json:"content"`
-	} `json:"candidates"`
-	PromptFeedback any `json:"promptFeedback"`
+	Candidates     []genCandidate `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason   string            `json:"blockReason"`
+		SafetyRatings []genSafetyRating `json:"safetyRatings"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+// finish reasons that mean the answer was withheld rather than finished
+var geminiBlocked = map[string]bool{
+	"SAFETY":             true,
+	"RECITATION":         true,
+	"BLOCKLIST":          true,
+	"PROHIBITED_CONTENT": true,
+	"SPII":               true,
+	"IMAGE_SAFETY":       true,
 }
 
-func CallGemini(ctx context.Context, message string) (string, error) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("GEMINI_API_KEY not set")
+// blocked reports a refused prompt or a withheld candidate.
+func (r genResp) blocked() error {
+	if f := r.PromptFeedback; f != nil && f.BlockReason != "" {
+		return &BlockedError{Provider: "gemini", Reason: f.BlockReason, Categories: flagged(f.SafetyRatings)}
+	}
+	if len(r.Candidates) > 0 && geminiBlocked[r.Candidates[0].FinishReason] {
+		c := r.Candidates[0]
+		return &BlockedError{Provider: "gemini", Reason: c.FinishReason, Categories: flagged(c.SafetyRatings)}
 	}
+	return nil
+}
 
-	url := "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+// flagged lists the categories Gemini blocked on, or rated MEDIUM/HIGH.
+func flagged(ratings []genSafetyRating) []string {
+	var out []string
+	for _, r := range ratings {
+		if r.Blocked || r.Probability == "MEDIUM" || r.Probability == "HIGH" {
+			out = append(out, strings.TrimPrefix(r.Category, "HARM_CATEGORY_"))
+		}
+	}
+	return out
+}
 
-	body := genReq{
-		Contents: []genContent{
-			{Role: "user", Parts: []genPart{{Text: message}}},
-		},
+// text joins the answer parts; long answers can come split over several.
+func (c genCandidate) text() string {
+	var b strings.Builder
+	for _, p := range c.Content.Parts {
+		if !p.Thought {
+			b.WriteString(p.Text)
+		}
 	}
-	jsonBody, _ := json.Marshal(body)
+	return b.String()
+}
 
-	req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-goog-api-key", apiKey)
+type Gemini struct {
+	cfg     config.AIConfig
+	baseURL string
+	client  *http.Client
+}
 
-	client := &http.Client{Timeout: 20 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("gemini request failed: %w", err)
+func newGemini(cfg *config.AIConfig) (*Gemini, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("gemini needs an API key (GEMINI_API_KEY or AI_API_KEY)")
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("gemini error %d: %s", resp.StatusCode, string(b))
+	base := cfg.BaseURL
+	if base == "" {
+		base = geminiBaseURL
 	}
+	return &Gemini{cfg: *cfg, baseURL: strings.TrimRight(base, "/"), client: &http.Client{}}, nil
+}
+
+func (g *Gemini) Name() string { return "gemini" }
+
+func (g *Gemini) Generate(ctx context.Context, req Request) (Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.Timeout)
+	defer cancel()
 
+	url := fmt.Sprintf("%s/models/%s:generateContent", g.baseURL, g.cfg.Model)
 	var out genResp
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if err := postJSON(ctx, g.client, g.cfg.MaxRetries, "gemini", url, g.headers(), g.body(req), &out); err != nil {
+		return Response{}, err
+	}
+
+	if err := out.blocked(); err != nil {
+		return Response{}, err
 	}
 	if len(out.Candidates) == 0 {
-		return "", fmt.Errorf("no candidates returned")
+		return Response{}, fmt.Errorf("no candidates returned")
+	}
+	cand := out.Candidates[0]
+	var calls []ToolCall
+	for _, p := range cand.Content.Parts {
+		if p.FunctionCall != nil {
+			calls = append(calls, ToolCall{
+				ID:   fmt.Sprintf("call_%d", len(calls)+1),
+				Name: p.FunctionCall.Name,
+				Args: rawArgs(string(p.FunctionCall.Args)),
+			})
+		}
 	}
-	parts := out.Candidates[0].Content.Parts
-	if len(parts) == 0 || parts[0].Text == "" {
-		return "", fmt.Errorf("empty candidate content")
+	if len(calls) > 0 {
+		return Response{Model: g.model(out), Usage: out.usage(), ToolCalls: calls}, nil
 	}
-	return parts[0].Text, nil
+	text := cand.text()
+	if text == "" {
+		if cand.FinishReason == "MAX_TOKENS" {
+			return Response{}, ErrTruncated
+		}
+		return Response{}, fmt.Errorf("empty candidate content (finish reason %q)", cand.FinishReason)
+	}
+	return Response{Text: text, Model: g.model(out), Usage: out.usage()}, nil
+}
+
+// Stream uses streamGenerateContent with alt=sse; every event is a partial
+// genResp carrying the next bit of text.
+func (g *Gemini) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (Response, error) {
+	req.Tools = nil
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.Timeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", g.baseURL, g.cfg.Model)
+	body, err := post(ctx, g.client, g.cfg.MaxRetries, "gemini", url, g.headers(), g.body(req))
+	if err != nil {
+		return Response{}, err
+	}
+	defer body.Close()
+
+	var text strings.Builder
+	var last genResp
+	err = readSSE(body, "gemini", func(data []byte) error {
+		var chunk genResp
+		if err := decodeChunk("gemini", data, &chunk); err != nil {
+			return err
+		}
+		last = chunk
+		// a block can come after some text has already gone out; the
+		// caller reports the error in place of the rest
+		if err := chunk.blocked(); err != nil {
+			return err
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+		if delta := chunk.Candidates[0].text(); delta != "" {
+			text.WriteString(delta)
+			return onDelta(delta)
+		}
+		return nil
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	if text.Len() == 0 {
+		if len(last.Candidates) > 0 && last.Candidates[0].FinishReason == "MAX_TOKENS" {
+			return Response{}, ErrTruncated
+		}
+		return Response{}, fmt.Errorf("empty candidate content")
+	}
+	// usageMetadata on the final chunk covers the whole answer
+	return Response{Text: text.String(), Model: g.model(last), Usage: last.usage()}, nil
+}
+
+func (g *Gemini) headers() map[string]string {
+	return map[string]string{"X-goog-api-key": g.cfg.APIKey}
+}
+
+func (g *Gemini) body(req Request) genReq {
+	body := genReq{
+		GenerationConfig: genConfig{Temperature: g.cfg.Temperature, MaxOutputTokens: g.cfg.MaxTokens},
+	}
+	if req.System != "" {
+		body.SystemInstruction = &genContent{Parts: []genPart{{Text: req.System}}}
+	}
+	for _, m := range req.Messages {
+		switch m.Role {
+		case RoleAssistant:
+			c := genContent{Role: "model"}
+			if m.Content != "" {
+				c.Parts = append(c.Parts, genPart{Text: m.Content})
+			}
+			for _, call := range m.ToolCalls {
+				c.Parts = append(c.Parts, genPart{FunctionCall: &genFunctionCall{Name: call.Name, Args: call.Args}})
+			}
+			body.Contents = append(body.Contents, c)
+		case RoleTool:
+			part := genPart{FunctionResponse: &genFunctionResponse{
+				Name:     m.ToolName,
+				Response: map[string]any{"content": json.RawMessage(m.Content)},
+			}}
+			// answers to parallel calls go back together in one turn
+			if n := len(body.Contents); n > 0 && isFunctionResponse(body.Contents[n-1]) {
+				body.Contents[n-1].Parts = append(body.Contents[n-1].Parts, part)
+				continue
+			}
+			body.Contents = append(body.Contents, genContent{Role: "user", Parts: []genPart{part}})
+		default:
+			body.Contents = append(body.Contents, genContent{Role: "user", Parts: []genPart{{Text: m.Content}}})
+		}
+	}
+	if req.Schema != nil {
+		body.GenerationConfig.ResponseMimeType = "application/json"
+		body.GenerationConfig.ResponseSchema = req.Schema
+	}
+	if len(req.Tools) > 0 {
+		decls := make([]genFunctionDecl, len(req.Tools))
+		for i, t := range req.Tools {
+			decls[i] = genFunctionDecl{Name: t.Name, Description: t.Description, Parameters: t.Parameters}
+		}
+		body.Tools = []genTool{{FunctionDeclarations: decls}}
+	}
+	return body
+}
+
+func (g *Gemini) model(out genResp) string {
+	if out.ModelVersion != "" {
+		return out.ModelVersion
+	}
+	return g.cfg.Model
+}
+
+func (r genResp) usage() Usage {
+	return Usage{PromptTokens: r.UsageMetadata.PromptTokenCount, CompletionTokens: r.UsageMetadata.CandidatesTokenCount}
 }
 
-// Convenience wrapper matching your old signature
-func CallGeminiSimple(message string) (string, error) {
-	return CallGemini(context.Background(), message)
+func isFunctionResponse(c genContent) bool {
+	return len(c.Parts) > 0 && c.Parts[0].FunctionResponse != nil
 }
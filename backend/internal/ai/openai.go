@@ -0,0 +1,243 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+)
+
+const openAIBaseURL = "https://api.openai.com/v1"
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	Refusal    string         `json:"refusal,omitempty"` // set instead of content when the model declines
+}
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // always "function"
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON encoded as a string
+	} `json:"function"`
+}
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+type chatReq struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	MaxTokens      int             `json:"max_tokens"`
+	Tools          []chatTool      `json:"tools,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+}
+type responseFormat struct {
+	Type       string `json:"type"` // "json_schema"
+	JSONSchema struct {
+		Name   string         `json:"name"`
+		Schema map[string]any `json:"schema"`
+	} `json:"json_schema"`
+}
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+type chatResp struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+// chatChunk is one streamed event; usage only arrives on the last one, and
+// only from servers that honour stream_options.
+type chatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+			Refusal string `json:"refusal"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+// OpenAI speaks the /chat/completions API. Besides OpenAI itself that covers
+// local servers like Ollama and llama.cpp, which usually need no key.
+type OpenAI struct {
+	cfg     config.AIConfig
+	baseURL string
+	client  *http.Client
+}
+
+func newOpenAI(cfg *config.AIConfig) (*OpenAI, error) {
+	base := cfg.BaseURL
+	if base == "" {
+		base = openAIBaseURL
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("api.openai.com needs an API key (OPENAI_API_KEY or AI_API_KEY)")
+		}
+	}
+	return &OpenAI{cfg: *cfg, baseURL: strings.TrimRight(base, "/"), client: &http.Client{}}, nil
+}
+
+func (o *OpenAI) Name() string { return "openai" }
+
+func (o *OpenAI) Generate(ctx context.Context, req Request) (Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	var out chatResp
+	if err := postJSON(ctx, o.client, o.cfg.MaxRetries, "openai", o.baseURL+"/chat/completions", o.headers(), o.body(req), &out); err != nil {
+		return Response{}, err
+	}
+	if len(out.Choices) == 0 {
+		return Response{}, fmt.Errorf("empty completion")
+	}
+	choice := out.Choices[0]
+	msg := choice.Message
+	if choice.FinishReason == "content_filter" || msg.Refusal != "" {
+		return Response{}, &BlockedError{Provider: "openai", Reason: refusalReason(choice.FinishReason)}
+	}
+	if msg.Content == "" && len(msg.ToolCalls) == 0 {
+		if choice.FinishReason == "length" {
+			return Response{}, ErrTruncated
+		}
+		return Response{}, fmt.Errorf("empty completion")
+	}
+	resp := Response{
+		Text:  msg.Content,
+		Model: o.model(out.Model),
+		Usage: Usage{PromptTokens: out.Usage.PromptTokens, CompletionTokens: out.Usage.CompletionTokens},
+	}
+	for _, c := range msg.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: c.ID, Name: c.Function.Name, Args: rawArgs(c.Function.Arguments)})
+	}
+	return resp, nil
+}
+
+func (o *OpenAI) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (Response, error) {
+	req.Tools = nil
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	in := o.body(req)
+	in.Stream = true
+	in.StreamOptions = &streamOptions{IncludeUsage: true}
+	body, err := post(ctx, o.client, o.cfg.MaxRetries, "openai", o.baseURL+"/chat/completions", o.headers(), in)
+	if err != nil {
+		return Response{}, err
+	}
+	defer body.Close()
+
+	var text strings.Builder
+	var model, finish string
+	var usage Usage
+	err = readSSE(body, "openai", func(data []byte) error {
+		var chunk chatChunk
+		if err := decodeChunk("openai", data, &chunk); err != nil {
+			return err
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = Usage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		}
+		if len(chunk.Choices) == 0 {
+			return nil
+		}
+		c := chunk.Choices[0]
+		if c.FinishReason == "content_filter" || c.Delta.Refusal != "" {
+			return &BlockedError{Provider: "openai", Reason: refusalReason(c.FinishReason)}
+		}
+		if c.FinishReason != "" {
+			finish = c.FinishReason
+		}
+		if c.Delta.Content == "" {
+			return nil
+		}
+		text.WriteString(c.Delta.Content)
+		return onDelta(c.Delta.Content)
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	if text.Len() == 0 {
+		if finish == "length" {
+			return Response{}, ErrTruncated
+		}
+		return Response{}, fmt.Errorf("empty completion")
+	}
+	return Response{Text: text.String(), Model: o.model(model), Usage: usage}, nil
+}
+
+// refusalReason is "content_filter" when the provider's filter cut in, and
+// "refusal" when the model itself declined.
+func refusalReason(finish string) string {
+	if finish == "content_filter" {
+		return finish
+	}
+	return "refusal"
+}
+
+func (o *OpenAI) headers() map[string]string {
+	headers := map[string]string{}
+	if o.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + o.cfg.APIKey
+	}
+	return headers
+}
+
+func (o *OpenAI) body(req Request) chatReq {
+	body := chatReq{Model: o.cfg.Model, Temperature: o.cfg.Temperature, MaxTokens: o.cfg.MaxTokens}
+	if req.System != "" {
+		body.Messages = append(body.Messages, chatMessage{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		cm := chatMessage{Role: string(m.Role), Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			tc := chatToolCall{ID: call.ID, Type: "function"}
+			tc.Function.Name, tc.Function.Arguments = call.Name, string(call.Args)
+			cm.ToolCalls = append(cm.ToolCalls, tc)
+		}
+		body.Messages = append(body.Messages, cm)
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = &responseFormat{Type: "json_schema"}
+		body.ResponseFormat.JSONSchema.Name = "reply"
+		body.ResponseFormat.JSONSchema.Schema = req.Schema
+	}
+	for _, t := range req.Tools {
+		ct := chatTool{Type: "function"}
+		ct.Function.Name, ct.Function.Description, ct.Function.Parameters = t.Name, t.Description, t.Parameters
+		body.Tools = append(body.Tools, ct)
+	}
+	return body
+}
+
+func (o *OpenAI) model(reported string) string {
+	if reported != "" {
+		return reported
+	}
+	return o.cfg.Model
+}
@@ -0,0 +1,244 @@
+// Package ai talks to LLM backends through one Provider interface so the
+// rest of the app doesn't care whether Gemini, an OpenAI-compatible server
+// or the fake is answering.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	// RoleTool messages carry a tool result back to the model
+	RoleTool Role = "tool"
+)
+
+type Message struct {
+	Role    Role
+	Content string
+	// Assistant turns that asked for tools
+	ToolCalls []ToolCall
+	// RoleTool turns: which call this answers. Content is the JSON result.
+	ToolCallID string
+	ToolName   string
+}
+
+// Request is one completion call. System is sent the way each backend
+// expects (system instruction / system message). Tools are only offered by
+// Generate; Stream ignores them.
+type Request struct {
+	System   string
+	Messages []Message
+	Tools    []Tool
+	// Schema asks for a JSON reply matching this JSON Schema instead of
+	// prose. Same subset as Tool.Parameters.
+	Schema map[string]any
+}
+
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type Response struct {
+	Text  string
+	Model string
+	Usage Usage
+	// Non-empty when the model wants tools run before it answers
+	ToolCalls []ToolCall
+}
+
+// DeltaFunc receives streamed text as it arrives. Returning an error stops
+// the stream and is passed back from Stream.
+type DeltaFunc func(text string) error
+
+type Provider interface {
+	// Name is the provider id from config, e.g. "gemini".
+	Name() string
+	Generate(ctx context.Context, req Request) (Response, error)
+	// Stream is Generate, but hands out text as it's produced. The returned
+	// Response holds the full text and usage once the stream is done.
+	Stream(ctx context.Context, req Request, onDelta DeltaFunc) (Response, error)
+}
+
+// ErrNotConfigured means the AI backend can't be used (e.g. missing key).
+var ErrNotConfigured = errors.New("AI provider not configured")
+
+// APIError is a non-2xx answer from the upstream API. Body is for logs only.
+type APIError struct {
+	Provider string
+	Status   int
+	Body     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s error %d: %s", e.Provider, e.Status, e.Body)
+}
+
+// Temporary is true for rate limits and server errors, which are retried.
+func (e *APIError) Temporary() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// BlockedError means the provider refused the prompt or withheld the answer
+// on safety grounds. Categories are the ones it flagged, if it said.
+type BlockedError struct {
+	Provider   string
+	Reason     string // e.g. "SAFETY", "PROHIBITED_CONTENT", "content_filter"
+	Categories []string
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("%s blocked the request: %s", e.Provider, e.Reason)
+	if len(e.Categories) > 0 {
+		msg += " (" + strings.Join(e.Categories, ", ") + ")"
+	}
+	return msg
+}
+
+// ErrTruncated means the answer hit max_tokens before any usable text.
+var ErrTruncated = errors.New("AI answer cut off by the token limit")
+
+// New builds the provider selected in cfg.
+func New(cfg *config.AIConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "gemini":
+		return newGemini(cfg)
+	case "openai":
+		return newOpenAI(cfg)
+	case "fake":
+		return Fake{}, nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+	}
+}
+
+// NewOrDisabled is New, but falls back to a provider that always returns
+// ErrNotConfigured so the server still starts without AI credentials.
+func NewOrDisabled(cfg *config.AIConfig) Provider {
+	p, err := New(cfg)
+	if err != nil {
+		log.Printf("⚠️ AI disabled: %v", err)
+		return disabled{name: cfg.Provider, err: err}
+	}
+	if cfg.Provider == "fake" {
+		log.Println("🤖 AI provider: fake (canned replies)")
+	} else {
+		log.Printf("🤖 AI provider: %s (%s)", p.Name(), cfg.Model)
+	}
+	return p
+}
+
+type disabled struct {
+	name string
+	err  error
+}
+
+func (d disabled) Name() string { return d.name }
+
+func (d disabled) Generate(ctx context.Context, req Request) (Response, error) {
+	return Response{}, fmt.Errorf("%w: %v", ErrNotConfigured, d.err)
+}
+
+func (d disabled) Stream(ctx context.Context, req Request, onDelta DeltaFunc) (Response, error) {
+	return d.Generate(ctx, req)
+}
+
+// postJSON sends in as JSON and decodes a 200 answer into out.
+func postJSON(ctx context.Context, client *http.Client, retries int, provider, url string, headers map[string]string, in, out any) error {
+	body, err := post(ctx, client, retries, provider, url, headers, in)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", provider, err)
+	}
+	return nil
+}
+
+const (
+	retryBase = 500 * time.Millisecond
+	retryMax  = 8 * time.Second // longer Retry-After hints aren't waited for
+)
+
+// post sends in as JSON and returns the body of a 200 answer; the caller
+// closes it. Rate limits, 5xx and network errors are retried up to retries
+// times with exponential backoff, within ctx's deadline.
+func post(ctx context.Context, client *http.Client, retries int, provider, url string, headers map[string]string, in any) (io.ReadCloser, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", provider, err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		rc, wait, err := send(ctx, client, provider, url, headers, body)
+		if err == nil {
+			return rc, nil
+		}
+		if wait < 0 || attempt >= retries || ctx.Err() != nil {
+			return nil, err
+		}
+		if wait == 0 {
+			wait = retryBase << attempt
+			wait += time.Duration(rand.Int64N(int64(wait / 2)))
+		}
+		if deadline, ok := ctx.Deadline(); (ok && time.Until(deadline) < wait) || wait > retryMax {
+			return nil, err
+		}
+		log.Printf("⚠️ %v; retry %d/%d in %s", err, attempt+1, retries, wait.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// send makes one attempt. wait is negative when retrying is pointless, and
+// positive when the server said how long to back off.
+func send(ctx context.Context, client *http.Client, provider, url string, headers map[string]string, body []byte) (io.ReadCloser, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to build %s request: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		apiErr := &APIError{Provider: provider, Status: resp.StatusCode, Body: string(b)}
+		if !apiErr.Temporary() {
+			return nil, -1, apiErr
+		}
+		var wait time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+		return nil, wait, apiErr
+	}
+	return resp.Body, 0, nil
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// readSSE calls fn with the JSON payload of every `data:` event in r until
+// the body ends or an OpenAI-style [DONE] marker arrives. Multi-line data
+// fields are joined as the spec says.
+func readSSE(r io.Reader, provider string, fn func(data []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var data []byte
+	flush := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		d := data
+		data = nil
+		return fn(d)
+	}
+
+	for sc.Scan() {
+		line := sc.Bytes()
+		switch {
+		case len(line) == 0:
+			if err := flush(); err != nil {
+				return err
+			}
+		case bytes.HasPrefix(line, []byte("data:")):
+			v := bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" "))
+			if bytes.Equal(v, []byte("[DONE]")) {
+				return nil
+			}
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, v...)
+		}
+		// event:, id:, retry: and comments aren't used by either API
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s stream broken: %w", provider, err)
+	}
+	return flush()
+}
+
+func decodeChunk(provider string, data []byte, out any) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s stream chunk: %w", provider, err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadSSE(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"events", "data: {\"a\":1}\n\ndata: {\"a\":2}\n\n", []string{`{"a":1}`, `{"a":2}`}},
+		{"no space after colon", "data:{\"a\":1}\n\n", []string{`{"a":1}`}},
+		{"multi-line data", "data: {\"a\":\ndata: 1}\n\n", []string{"{\"a\":\n1}"}},
+		{"other fields and comments", ": keep-alive\nevent: message\nid: 7\ndata: x\n\n", []string{"x"}},
+		{"done marker", "data: x\n\ndata: [DONE]\n\ndata: y\n\n", []string{"x"}},
+		{"no trailing blank line", "data: x\n\ndata: y", []string{"x", "y"}},
+		{"CRLF line endings", "data: x\r\n\r\n", []string{"x"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		err := readSSE(strings.NewReader(tt.body), "test", func(data []byte) error {
+			got = append(got, string(data))
+			return nil
+		})
+		if err != nil || strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q (err %v), want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestReadSSEStopsOnCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := readSSE(strings.NewReader("data: x\n\ndata: y\n\n"), "test", func([]byte) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("got %v after %d calls, want the callback's error after 1", err, calls)
+	}
+}
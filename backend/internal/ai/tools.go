@@ -0,0 +1,33 @@
+package ai
+
+import "encoding/json"
+
+// Tool is a function the model may ask us to call. Parameters is a JSON
+// Schema object; stick to type/properties/required/enum/items/description,
+// which both Gemini and OpenAI understand.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// ToolCall is one function call requested by the model. Gemini doesn't
+// number its calls, so IDs are made up there.
+type ToolCall struct {
+	ID   string
+	Name string
+	Args json.RawMessage
+}
+
+// rawArgs keeps malformed argument strings as a JSON string so they still
+// marshal; decoding them then fails in the tool with a readable error.
+func rawArgs(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("{}")
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
@@ -0,0 +1,163 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+const summaryInstructions = `Summarize this conversation between an FPL manager and their assistant.
+Keep decisions, players and plans that were discussed, and any preferences the manager stated.
+Write at most 120 words of plain prose. If there is an earlier summary, merge it in.`
+
+// Chat runs multi-turn conversations: it replays stored history into the
+// provider, keeping it under a token budget by summarizing the oldest turns.
+type Chat struct {
+	store   *repository.Store
+	ai      ai.Provider
+	prompts *Builder
+	budget  int // tokens of history replayed per turn
+}
+
+func NewChat(store *repository.Store, provider ai.Provider, prompts *Builder, historyTokens int) *Chat {
+	return &Chat{store: store, ai: provider, prompts: prompts, budget: historyTokens}
+}
+
+// StorageError marks Reply failures on our side rather than the provider's.
+type StorageError struct{ Err error }
+
+func (e *StorageError) Error() string { return e.Err.Error() }
+func (e *StorageError) Unwrap() error { return e.Err }
+
+// Turn is the outcome of Reply: both stored messages plus the raw response.
+type Turn struct {
+	Question model.ConversationMessage
+	Answer   model.ConversationMessage
+	Response ai.Response
+	Prompt   string // template versions
+}
+
+// Reply answers text within conv and stores both sides of the exchange.
+// Nothing is stored when the provider fails, so the client can retry.
+func (c *Chat) Reply(ctx context.Context, conv *model.Conversation, text string, squad *Squad) (Turn, error) {
+	history, err := c.store.Conversations.Messages(ctx, conv.ID, conv.SummarizedThrough)
+	if err != nil {
+		return Turn{}, &StorageError{fmt.Errorf("❌ failed to load conversation %d: %w", conv.ID, err)}
+	}
+	question := model.ConversationMessage{Role: string(ai.RoleUser), Content: text}
+
+	older, recent := SplitHistory(history, c.budget-EstimateTokens(text))
+	var summaryUsage ai.Usage
+	if len(older) > 0 {
+		// A failed summary isn't fatal: the old turns are just dropped from
+		// this prompt and we try again next time.
+		var summary string
+		summary, summaryUsage, err = c.summarize(ctx, conv.Summary, older)
+		if err != nil {
+			log.Printf("⚠️ conversation %d: summarizing %d messages failed: %v", conv.ID, len(older), err)
+		} else {
+			conv.Summary = summary
+			conv.SummarizedThrough = older[len(older)-1].ID
+		}
+	}
+
+	system, err := c.prompts.SystemPrompt(ctx, squad, time.Now())
+	if err != nil {
+		return Turn{}, &StorageError{err}
+	}
+	if conv.Summary != "" {
+		system.Text += "\n## Earlier in this conversation\n" + conv.Summary + "\n"
+	}
+
+	msgs := make([]ai.Message, 0, len(recent)+1)
+	for _, m := range recent {
+		msgs = append(msgs, ai.Message{Role: ai.Role(m.Role), Content: m.Content})
+	}
+	msgs = append(msgs, ai.Message{Role: ai.RoleUser, Content: text})
+
+	resp, err := c.ai.Generate(ctx, ai.Request{System: system.Text, Messages: msgs})
+	if err != nil {
+		return Turn{}, err
+	}
+	// the summary is billed too, so it counts towards this turn
+	resp.Usage.PromptTokens += summaryUsage.PromptTokens
+	resp.Usage.CompletionTokens += summaryUsage.CompletionTokens
+
+	answer := model.ConversationMessage{Role: string(ai.RoleAssistant), Content: resp.Text, Model: resp.Model}
+	stored := []model.ConversationMessage{question, answer}
+	if err := c.store.Conversations.AddMessages(ctx, conv.ID, stored); err != nil {
+		return Turn{}, &StorageError{fmt.Errorf("❌ failed to save messages: %w", err)}
+	}
+	if conv.Title == "" {
+		conv.Title = TitleFrom(text)
+	}
+	if err := c.store.Conversations.Update(ctx, conv); err != nil {
+		return Turn{}, &StorageError{fmt.Errorf("❌ failed to update conversation %d: %w", conv.ID, err)}
+	}
+	return Turn{Question: stored[0], Answer: stored[1], Response: resp, Prompt: system.Version}, nil
+}
+
+func (c *Chat) summarize(ctx context.Context, previous string, msgs []model.ConversationMessage) (string, ai.Usage, error) {
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("Earlier summary: " + previous + "\n\n")
+	}
+	for _, m := range msgs {
+		who := "Manager"
+		if m.Role == string(ai.RoleAssistant) {
+			who = "Assistant"
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", who, m.Content)
+	}
+	resp, err := c.ai.Generate(ctx, ai.Request{
+		System:   summaryInstructions,
+		Messages: []ai.Message{{Role: ai.RoleUser, Content: sb.String()}},
+	})
+	if err != nil {
+		return "", ai.Usage{}, err
+	}
+	return strings.TrimSpace(resp.Text), resp.Usage, nil
+}
+
+// EstimateTokens is a cheap stand-in for a tokenizer: roughly four
+// characters per token for English text.
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// SplitHistory keeps the newest messages that fit in budget tokens and
+// returns the rest as older. Recent history always starts on a user turn,
+// since providers expect conversations to open with the user.
+func SplitHistory(msgs []model.ConversationMessage, budget int) (older, recent []model.ConversationMessage) {
+	start, used := len(msgs), 0
+	for start > 0 {
+		t := EstimateTokens(msgs[start-1].Content)
+		if used+t > budget {
+			break
+		}
+		used += t
+		start--
+	}
+	for start < len(msgs) && msgs[start].Role != string(ai.RoleUser) {
+		start++
+	}
+	return msgs[:start], msgs[start:]
+}
+
+// TitleFrom derives a conversation title from its first question.
+func TitleFrom(text string) string {
+	const max = 60
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	r := []rune(text)
+	return strings.TrimSpace(string(r[:max-1])) + "…"
+}
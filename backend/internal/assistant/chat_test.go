@@ -0,0 +1,92 @@
+package assistant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository/memory"
+)
+
+func msg(id uint, role ai.Role, words int) model.ConversationMessage {
+	return model.ConversationMessage{ID: id, Role: string(role), Content: strings.Repeat("word ", words)}
+}
+
+func TestSplitHistory(t *testing.T) {
+	// 5 words = 25 chars ≈ 7 tokens each
+	history := []model.ConversationMessage{
+		msg(1, ai.RoleUser, 5), msg(2, ai.RoleAssistant, 5),
+		msg(3, ai.RoleUser, 5), msg(4, ai.RoleAssistant, 5),
+	}
+	tests := []struct {
+		budget int
+		older  int
+		first  uint // first recent message
+	}{
+		{100, 0, 1},
+		{28, 0, 1},
+		{21, 2, 3}, // three fit, but recent has to start on a user turn
+		{14, 2, 3},
+		{7, 4, 0},
+		{0, 4, 0},
+	}
+	for _, tt := range tests {
+		older, recent := SplitHistory(history, tt.budget)
+		if len(older) != tt.older || len(older)+len(recent) != len(history) {
+			t.Errorf("budget %d: %d older, %d recent, want %d older", tt.budget, len(older), len(recent), tt.older)
+			continue
+		}
+		if len(recent) > 0 && recent[0].ID != tt.first {
+			t.Errorf("budget %d: recent starts at %d, want %d", tt.budget, recent[0].ID, tt.first)
+		}
+	}
+}
+
+// countingAI sums the usage of every call it answers.
+type countingAI struct {
+	ai.Fake
+	calls int
+	usage ai.Usage
+}
+
+func (c *countingAI) Generate(ctx context.Context, req ai.Request) (ai.Response, error) {
+	resp, err := c.Fake.Generate(ctx, req)
+	c.calls++
+	c.usage.PromptTokens += resp.Usage.PromptTokens
+	c.usage.CompletionTokens += resp.Usage.CompletionTokens
+	return resp, err
+}
+
+func TestReplyCountsSummaryUsage(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	templates, err := prompts.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &countingAI{}
+	chat := NewChat(store, provider, NewBuilder(store, templates), 200)
+
+	conv := model.Conversation{UserID: 1}
+	if err := store.Conversations.Create(ctx, &conv); err != nil {
+		t.Fatal(err)
+	}
+	old := []model.ConversationMessage{msg(0, ai.RoleUser, 150), msg(0, ai.RoleAssistant, 150)}
+	if err := store.Conversations.AddMessages(ctx, conv.ID, old); err != nil {
+		t.Fatal(err)
+	}
+
+	turn, err := chat.Reply(ctx, &conv, "And now?", nil)
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if provider.calls != 2 || conv.Summary == "" {
+		t.Fatalf("want a summary call and an answer, got %d calls, summary %q", provider.calls, conv.Summary)
+	}
+	if turn.Response.Usage != provider.usage {
+		t.Errorf("turn usage %+v, want both calls %+v", turn.Response.Usage, provider.usage)
+	}
+}
@@ -0,0 +1,67 @@
+package assistant
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// InputError means user text is over the length limit.
+type InputError struct {
+	Max int
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("at most %d characters", e.Max)
+}
+
+// InputGuard cleans user text before it goes into a prompt. A nil guard
+// still cleans but doesn't limit the length.
+type InputGuard struct {
+	maxChars int
+}
+
+func NewInputGuard(maxChars int) *InputGuard {
+	return &InputGuard{maxChars: maxChars}
+}
+
+// Markers that chat templates and jailbreaks use to fake a system turn. The
+// persona prompt tells the model to treat user text as a question; these are
+// dropped so it doesn't have to see through them.
+var injection = []*regexp.Regexp{
+	regexp.MustCompile(`<\|[a-zA-Z_]{1,20}\|>`),          // <|im_start|>, <|system|>, <|endoftext|>
+	regexp.MustCompile(`(?i)\[/?INST\]|<</?SYS>>|</?s>`), // llama
+	regexp.MustCompile(`(?im)^[ \t]*(#{1,6}[ \t]*)?(system|assistant|developer)[ \t]*:`),
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget)[ \t]+(all[ \t]+)?(of[ \t]+)?(the[ \t]+|your[ \t]+)?(previous|prior|above|earlier|system)[ \t]+(instructions|prompts?|rules)\b`),
+}
+
+// Clean trims s, drops control and invisible characters and defuses
+// prompt-injection markers. Too long is an *InputError; the caller decides
+// whether empty is allowed.
+func (g *InputGuard) Clean(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if g != nil && g.maxChars > 0 && utf8.RuneCountInString(s) > g.maxChars {
+		return "", &InputError{Max: g.maxChars}
+	}
+
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r): // zero-width, bidi overrides, BOM
+			return -1
+		}
+		return r
+	}, s)
+	for _, re := range injection {
+		clean = re.ReplaceAllString(clean, "")
+	}
+	clean = strings.TrimSpace(clean)
+	if clean != s {
+		log.Printf("⚠️ Cleaned user input for the AI (%d -> %d chars)", utf8.RuneCountInString(s), utf8.RuneCountInString(clean))
+	}
+	return clean, nil
+}
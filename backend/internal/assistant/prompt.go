@@ -0,0 +1,415 @@
+// Package assistant turns FPL data into context for the AI so answers talk
+// about the user's real players, prices and fixtures instead of guessing.
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// Squad is what the client is looking at. Only ids are trusted; every stat
+// comes from our own tables.
+type Squad struct {
+	Starters   []uint
+	Bench      []uint
+	CaptainID  uint
+	ViceID     uint
+	BudgetLeft float64 // £m
+}
+
+const (
+	nextFixtures = 3  // per player
+	topFormCount = 10 // general pool shown alongside the squad
+	keyPlayers   = 2  // per team in match previews
+	previewTop   = 5  // captain picks and transfer movers in previews
+)
+
+// Prompt is rendered text plus the versions of the templates behind it,
+// e.g. "persona@1 squad@2".
+type Prompt struct {
+	Text    string
+	Version string
+}
+
+// Builder renders system prompts from the templates and the repositories.
+type Builder struct {
+	store   *repository.Store
+	prompts *prompts.Set
+}
+
+func NewBuilder(store *repository.Store, set *prompts.Set) *Builder {
+	return &Builder{store: store, prompts: set}
+}
+
+// SystemPrompt describes the squad (may be nil), upcoming fixtures and the
+// in-form player pool as of now.
+func (b *Builder) SystemPrompt(ctx context.Context, squad *Squad, now time.Time) (Prompt, error) {
+	return b.render(ctx, squad, now, false)
+}
+
+// ToolPrompt is SystemPrompt for a model that has Tools: the player pool is
+// left out since it can search for itself.
+func (b *Builder) ToolPrompt(ctx context.Context, squad *Squad, now time.Time) (Prompt, error) {
+	return b.render(ctx, squad, now, true)
+}
+
+func (b *Builder) render(ctx context.Context, squad *Squad, now time.Time, tools bool) (Prompt, error) {
+	d, err := loadData(ctx, b.store)
+	if err != nil {
+		return Prompt{}, err
+	}
+	return b.compose(d.view(squad, now, tools), "persona", "squad")
+}
+
+// Templates lists the prompt templates with their versions.
+func (b *Builder) Templates() []prompts.Info {
+	return b.prompts.List()
+}
+
+// Render renders one template on its own against live data, for checking
+// edits. Every variable is filled in; a nil squad gets a sample one.
+func (b *Builder) Render(ctx context.Context, name string, squad *Squad, freeTransfers int, tools bool, now time.Time) (Prompt, error) {
+	d, err := loadData(ctx, b.store)
+	if err != nil {
+		return Prompt{}, err
+	}
+	if squad == nil {
+		squad = d.sampleSquad()
+	}
+	v := d.view(squad, now, tools)
+	v.Candidates = d.candidates(*squad)
+	v.FreeTransfers = freeTransfers
+	v.Preview = d.preview()
+	return b.compose(v, name)
+}
+
+// compose renders the named templates in order, separated by blank lines.
+func (b *Builder) compose(v *view, names ...string) (Prompt, error) {
+	texts, versions := make([]string, len(names)), make([]string, len(names))
+	for i, name := range names {
+		var err error
+		if texts[i], versions[i], err = b.prompts.Render(name, v); err != nil {
+			return Prompt{}, err
+		}
+	}
+	return Prompt{Text: strings.Join(texts, "\n\n") + "\n", Version: strings.Join(versions, " ")}, nil
+}
+
+// view is what the templates see; see assets/prompts for how it's used.
+type view struct {
+	Today         string
+	NextGameweek  int
+	Tools         bool
+	Squad         *squadView
+	InForm        []playerView // empty when Tools is set
+	Candidates    []positionView
+	FreeTransfers int
+	Preview       *previewView
+}
+
+type squadView struct {
+	Starters   []playerView
+	Bench      []playerView
+	BudgetLeft float64
+}
+
+type playerView struct {
+	ID       uint
+	Unknown  bool // the id isn't in the database
+	Name     string
+	Team     string
+	Position string
+	Price    float64
+	Form     string
+	Points   int
+	LastGW   int
+	ICT      string
+	Owned    float64
+	In       int    // transfers in this gameweek
+	Out      int    // transfers out this gameweek
+	Next     string // e.g. "GW5 BOU (H) 2, GW6 ARS (A) 4"
+	Tag      string // "C" or "VC"
+}
+
+type positionView struct {
+	Position string
+	Players  []playerView
+}
+
+type previewView struct {
+	Gameweek     int
+	Fixtures     []fixtureView
+	Captains     []playerView // best form against the easiest opponents
+	TransfersIn  []playerView // most bought this gameweek
+	TransfersOut []playerView // most sold this gameweek
+}
+
+type fixtureView struct {
+	Kickoff    string // e.g. "Sat 15:00" (UTC)
+	Home       string
+	Away       string
+	HomeFDR    int
+	AwayFDR    int
+	KeyPlayers []playerView // best form on each side
+}
+
+func (d *data) view(squad *Squad, now time.Time, tools bool) *view {
+	v := &view{
+		Today:        now.UTC().Format("Mon 2 Jan 2006"),
+		NextGameweek: d.nextGameweek(),
+		Tools:        tools,
+	}
+	if squad != nil && len(squad.Starters)+len(squad.Bench) > 0 {
+		v.Squad = &squadView{
+			Starters:   d.squadPlayers(squad.Starters, squad),
+			Bench:      d.squadPlayers(squad.Bench, squad),
+			BudgetLeft: squad.BudgetLeft,
+		}
+	}
+	if !tools {
+		v.InForm = d.playerViews(d.topForm(topFormCount, nil))
+	}
+	return v
+}
+
+type data struct {
+	players  map[uint]model.Player
+	teams    map[uint]model.Team
+	upcoming map[uint][]model.Fixture // team id -> unfinished fixtures by kickoff
+}
+
+func loadData(ctx context.Context, store *repository.Store) (*data, error) {
+	players, err := store.Players.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("❌ failed to load players: %w", err)
+	}
+	teams, err := store.Teams.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("❌ failed to load teams: %w", err)
+	}
+	fixtures, err := store.Fixtures.ListUnfinished(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("❌ failed to load fixtures: %w", err)
+	}
+
+	d := &data{
+		players:  make(map[uint]model.Player, len(players)),
+		teams:    make(map[uint]model.Team, len(teams)),
+		upcoming: map[uint][]model.Fixture{},
+	}
+	for _, p := range players {
+		d.players[p.ID] = p
+	}
+	for _, t := range teams {
+		d.teams[t.ID] = t
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].KickoffTime.Before(*fixtures[j].KickoffTime) })
+	for _, f := range fixtures {
+		d.upcoming[f.TeamHID] = append(d.upcoming[f.TeamHID], f)
+		d.upcoming[f.TeamAID] = append(d.upcoming[f.TeamAID], f)
+	}
+	return d, nil
+}
+
+// nextGameweek is the event of the earliest unfinished fixture.
+func (d *data) nextGameweek() int {
+	best := 0
+	var first time.Time
+	for _, fs := range d.upcoming {
+		if len(fs) == 0 || fs[0].Event == nil {
+			continue
+		}
+		if best == 0 || fs[0].KickoffTime.Before(first) {
+			best, first = *fs[0].Event, *fs[0].KickoffTime
+		}
+	}
+	return best
+}
+
+func (d *data) squadPlayers(ids []uint, squad *Squad) []playerView {
+	out := make([]playerView, len(ids))
+	for i, id := range ids {
+		p, ok := d.players[id]
+		if !ok {
+			out[i] = playerView{ID: id, Unknown: true}
+			continue
+		}
+		out[i] = d.playerView(p)
+		switch id {
+		case squad.CaptainID:
+			out[i].Tag = "C"
+		case squad.ViceID:
+			out[i].Tag = "VC"
+		}
+	}
+	return out
+}
+
+func (d *data) playerView(p model.Player) playerView {
+	return playerView{
+		ID: p.ID, Name: p.WebName, Team: d.teams[p.TeamID].ShortName, Position: p.Position,
+		Price: p.CurrentPrice, Form: orZero(p.Form), Points: p.TotalPoints, LastGW: p.EventPoints,
+		ICT: orZero(p.IctIndex), Owned: p.SelectedByPercent, In: p.TransfersInEvent, Out: p.TransfersOutEvent,
+		Next: d.fixtureRun(p.TeamID),
+	}
+}
+
+func (d *data) playerViews(ps []model.Player) []playerView {
+	out := make([]playerView, len(ps))
+	for i, p := range ps {
+		out[i] = d.playerView(p)
+	}
+	return out
+}
+
+// preview lists the next gameweek's fixtures by kickoff; nil when none are
+// scheduled.
+func (d *data) preview() *previewView {
+	gw := d.nextGameweek()
+	if gw == 0 {
+		return nil
+	}
+	seen := map[int]bool{}
+	var fixtures []model.Fixture
+	for _, fs := range d.upcoming {
+		for _, f := range fs {
+			if f.Event != nil && *f.Event == gw && !seen[f.ID] {
+				seen[f.ID] = true
+				fixtures = append(fixtures, f)
+			}
+		}
+	}
+	sort.Slice(fixtures, func(i, j int) bool {
+		if !fixtures[i].KickoffTime.Equal(*fixtures[j].KickoffTime) {
+			return fixtures[i].KickoffTime.Before(*fixtures[j].KickoffTime)
+		}
+		return fixtures[i].ID < fixtures[j].ID
+	})
+
+	pv := &previewView{Gameweek: gw}
+	ease := map[uint]float64{} // per team, summed over a double gameweek
+	for _, f := range fixtures {
+		ease[f.TeamHID] += float64(6 - f.TeamHDifficulty)
+		ease[f.TeamAID] += float64(6 - f.TeamADifficulty)
+		fv := fixtureView{
+			Kickoff: f.KickoffTime.UTC().Format("Mon 15:04"),
+			Home:    d.teams[f.TeamHID].ShortName, Away: d.teams[f.TeamAID].ShortName,
+			HomeFDR: f.TeamHDifficulty, AwayFDR: f.TeamADifficulty,
+		}
+		for _, team := range []uint{f.TeamHID, f.TeamAID} {
+			fv.KeyPlayers = append(fv.KeyPlayers, d.playerViews(d.topForm(keyPlayers, func(p model.Player) bool { return p.TeamID == team }))...)
+		}
+		pv.Fixtures = append(pv.Fixtures, fv)
+	}
+
+	pv.Captains = d.playerViews(d.topBy(previewTop,
+		func(p model.Player) bool { return ease[p.TeamID] > 0 && p.Position != "GK" && p.Position != "DEF" },
+		func(p model.Player) float64 { return parseFloat(p.Form) * ease[p.TeamID] }))
+	pv.TransfersIn = d.playerViews(d.topBy(previewTop,
+		func(p model.Player) bool { return p.TransfersInEvent > 0 },
+		func(p model.Player) float64 { return float64(p.TransfersInEvent) }))
+	pv.TransfersOut = d.playerViews(d.topBy(previewTop,
+		func(p model.Player) bool { return p.TransfersOutEvent > 0 },
+		func(p model.Player) float64 { return float64(p.TransfersOutEvent) }))
+	return pv
+}
+
+// sampleSquad picks an in-form 15 (4-4-2 plus bench, at most 3 per club)
+// so templates can be rendered without a real squad.
+func (d *data) sampleSquad() *Squad {
+	starting := map[string]int{"GK": 1, "DEF": 4, "MID": 4, "FWD": 2}
+	s := &Squad{}
+	clubs := map[uint]int{}
+	for _, pos := range []string{"GK", "DEF", "MID", "FWD"} {
+		picked := 0
+		for _, p := range d.topForm(len(d.players), func(p model.Player) bool { return p.Position == pos }) {
+			if picked == squadShape[pos] {
+				break
+			}
+			if clubs[p.TeamID] == maxPerClub {
+				continue
+			}
+			clubs[p.TeamID]++
+			picked++
+			if picked <= starting[pos] {
+				s.Starters = append(s.Starters, p.ID)
+			} else {
+				s.Bench = append(s.Bench, p.ID)
+			}
+		}
+	}
+	if len(s.Starters) > 1 {
+		s.CaptainID, s.ViceID = s.Starters[len(s.Starters)-1], s.Starters[len(s.Starters)-2]
+	}
+	return s
+}
+
+func (d *data) fixtureRun(teamID uint) string {
+	fs := d.upcoming[teamID]
+	if len(fs) == 0 {
+		return "none scheduled"
+	}
+	if len(fs) > nextFixtures {
+		fs = fs[:nextFixtures]
+	}
+	parts := make([]string, len(fs))
+	for i, f := range fs {
+		opp, venue, fdr := f.TeamAID, "H", f.TeamHDifficulty
+		if f.TeamAID == teamID {
+			opp, venue, fdr = f.TeamHID, "A", f.TeamADifficulty
+		}
+		gw := ""
+		if f.Event != nil {
+			gw = "GW" + strconv.Itoa(*f.Event) + " "
+		}
+		parts[i] = fmt.Sprintf("%s%s (%s) %d", gw, d.teams[opp].ShortName, venue, fdr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// topForm returns the n best players by form among those keep accepts
+// (all when keep is nil).
+func (d *data) topForm(n int, keep func(model.Player) bool) []model.Player {
+	return d.topBy(n, keep, func(p model.Player) float64 { return parseFloat(p.Form) })
+}
+
+// topBy returns the n highest scoring players among those keep accepts.
+func (d *data) topBy(n int, keep func(model.Player) bool, score func(model.Player) float64) []model.Player {
+	all := make([]model.Player, 0, len(d.players))
+	for _, p := range d.players {
+		if keep == nil || keep(p) {
+			all = append(all, p)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		si, sj := score(all[i]), score(all[j])
+		if si != sj {
+			return si > sj
+		}
+		return all[i].ID < all[j].ID
+	})
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// ReportJobKind identifies gameweek report jobs in the job manager.
+const ReportJobKind = "gameweek-report"
+
+// ErrNoGameweek means no upcoming gameweek has fixtures yet.
+var ErrNoGameweek = errors.New("no upcoming gameweek with fixtures")
+
+// Reporter writes the weekly gameweek preview so the team can read one
+// summary instead of asking the assistant each time.
+type Reporter struct {
+	store   *repository.Store
+	ai      ai.Provider
+	prompts *Builder
+	meter   *Meter
+	queued  atomic.Bool // a QueueReport is waiting for the running job
+}
+
+func NewReporter(store *repository.Store, provider ai.Provider, prompts *Builder, meter *Meter) *Reporter {
+	return &Reporter{store: store, ai: provider, prompts: prompts, meter: meter}
+}
+
+// Generate writes the report for the next gameweek. Unless force is set a
+// report that already covers the latest imported data is left alone;
+// written says which happened.
+func (r *Reporter) Generate(ctx context.Context, force bool) (rep model.GameweekReport, written bool, err error) {
+	d, err := loadData(ctx, r.store)
+	if err != nil {
+		return rep, false, &StorageError{err}
+	}
+	preview := d.preview()
+	if preview == nil || len(preview.Fixtures) == 0 {
+		return rep, false, ErrNoGameweek
+	}
+	gw := preview.Gameweek
+
+	asOf, err := r.store.FetchStates.LastFetchedAt(ctx)
+	if err != nil {
+		return rep, false, &StorageError{fmt.Errorf("❌ failed to read data version: %w", err)}
+	}
+	if !force {
+		rep, err = r.store.Reports.Get(ctx, gw)
+		if err == nil && !rep.DataAsOf.Before(asOf) {
+			return rep, false, nil
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return rep, false, &StorageError{fmt.Errorf("❌ failed to load GW%d report: %w", gw, err)}
+		}
+	}
+
+	v := d.view(nil, time.Now(), false)
+	v.Preview = preview
+	system, err := r.prompts.compose(v, "persona", "preview")
+	if err != nil {
+		return rep, false, &StorageError{err}
+	}
+	resp, err := r.ai.Generate(ctx, ai.Request{
+		System:   system.Text,
+		Messages: []ai.Message{{Role: ai.RoleUser, Content: fmt.Sprintf("Write the GW%d preview.", gw)}},
+	})
+	if err != nil {
+		return rep, false, err
+	}
+	r.meter.Record(ctx, Caller{Client: "job"}, "gameweek-report", system.Version, resp, false)
+
+	rep = model.GameweekReport{
+		Gameweek:      gw,
+		Markdown:      stripFence(resp.Text),
+		Provider:      r.ai.Name(),
+		Model:         resp.Model,
+		PromptVersion: system.Version,
+		DataAsOf:      asOf,
+	}
+	if err := r.store.Reports.Save(ctx, &rep); err != nil {
+		return rep, false, &StorageError{fmt.Errorf("❌ failed to save GW%d report: %w", gw, err)}
+	}
+	if saved, err := r.store.Reports.Get(ctx, gw); err == nil {
+		rep = saved // CreatedAt of the first version when replacing
+	}
+	return rep, true, nil
+}
+
+// SubmitReport queues Generate on m. Runs after an import (force false) skip
+// quietly when there's nothing new to write or no AI configured; manual ones
+// report that as a failure.
+func (r *Reporter) SubmitReport(m *jobs.Manager, trigger string, force bool) (*jobs.Job, error) {
+	return m.Submit(ReportJobKind, trigger, func(ctx context.Context, job *jobs.Job) error {
+		job.Phase("report", 1)
+		rep, written, err := r.Generate(ctx, force)
+		switch {
+		case !force && (errors.Is(err, ErrNoGameweek) || errors.Is(err, ai.ErrNotConfigured)):
+			job.Warn(err.Error())
+			return nil
+		case err != nil:
+			return fmt.Errorf("❌ gameweek report failed: %w", err)
+		}
+		job.Advance(1)
+		if written {
+			log.Printf("📰 GW%d report written (%s, %d chars)", rep.Gameweek, rep.Model, len(rep.Markdown))
+		}
+		return nil
+	})
+}
+
+// QueueReport is SubmitReport without force, for hooks that must not lose a
+// run: if a report job is already going, it waits for that one and submits
+// again, so data imported meanwhile still makes it into a report. Hooks
+// firing while one is waiting are folded into that run.
+func (r *Reporter) QueueReport(m *jobs.Manager, trigger string) error {
+	job, err := r.SubmitReport(m, trigger, false)
+	if !errors.Is(err, jobs.ErrAlreadyRunning) {
+		return err
+	}
+	if !r.queued.CompareAndSwap(false, true) {
+		return nil
+	}
+	go func() {
+		for {
+			<-job.Done()
+			r.queued.Store(false) // hooks from here on are seen by the next run
+			next, err := r.SubmitReport(m, trigger, false)
+			if !errors.Is(err, jobs.ErrAlreadyRunning) {
+				if err != nil {
+					log.Printf("⚠️ Failed to queue gameweek report: %v", err)
+				}
+				return
+			}
+			if !r.queued.CompareAndSwap(false, true) {
+				return // another hook is waiting already
+			}
+			job = next
+		}
+	}()
+	return nil
+}
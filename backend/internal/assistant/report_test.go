@@ -0,0 +1,143 @@
+package assistant
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository/memory"
+)
+
+// gatedAI signals asked when a call comes in, then answers once per value
+// sent on gate, counting calls.
+type gatedAI struct {
+	ai.Fake
+	asked chan struct{}
+	gate  chan struct{}
+	calls atomic.Int32
+}
+
+func (g *gatedAI) Generate(ctx context.Context, req ai.Request) (ai.Response, error) {
+	select {
+	case g.asked <- struct{}{}:
+	case <-ctx.Done():
+		return ai.Response{}, ctx.Err()
+	}
+	select {
+	case <-g.gate:
+	case <-ctx.Done():
+		return ai.Response{}, ctx.Err()
+	}
+	g.calls.Add(1)
+	return g.Fake.Generate(ctx, req)
+}
+
+func newReportStore(t *testing.T) *repository.Store {
+	t.Helper()
+	store := memory.NewStore()
+	gw := 10
+	kickoff := time.Now().Add(48 * time.Hour)
+	ctx := context.Background()
+	err := errors.Join(
+		store.Teams.Upsert(ctx, []model.Team{{ID: 1, Name: "Arsenal", ShortName: "ARS"}, {ID: 2, Name: "Liverpool", ShortName: "LIV"}}),
+		store.Fixtures.Upsert(ctx, []model.Fixture{{ID: 1, Event: &gw, KickoffTime: &kickoff, TeamHID: 1, TeamAID: 2, TeamHDifficulty: 4, TeamADifficulty: 4}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func newTestReporter(t *testing.T, store *repository.Store, provider ai.Provider) *Reporter {
+	t.Helper()
+	templates, err := prompts.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewReporter(store, provider, NewBuilder(store, templates), NewMeter(store, provider.Name(), 0, 0, 0))
+}
+
+// imported records an import of fresh data at at.
+func imported(t *testing.T, store *repository.Store, at time.Time) {
+	t.Helper()
+	if err := store.FetchStates.Save(context.Background(), model.FetchState{Endpoint: "bootstrap-static", FetchedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReportRewrittenAfterNewData(t *testing.T) {
+	ctx := context.Background()
+	store := newReportStore(t)
+	provider := &countingAI{}
+	reporter := newTestReporter(t, store, provider)
+
+	first := time.Now().Add(-time.Hour)
+	imported(t, store, first)
+	if _, written, err := reporter.Generate(ctx, false); err != nil || !written {
+		t.Fatalf("first report: written %t, %v", written, err)
+	}
+	if _, written, err := reporter.Generate(ctx, false); err != nil || written {
+		t.Fatalf("same data: written %t, %v; want the report kept", written, err)
+	}
+
+	imported(t, store, first.Add(30*time.Minute))
+	rep, written, err := reporter.Generate(ctx, false)
+	if err != nil || !written {
+		t.Fatalf("after an import: written %t, %v; want a new report", written, err)
+	}
+	if !rep.DataAsOf.Equal(first.Add(30*time.Minute)) || provider.calls != 2 {
+		t.Errorf("data as of %s after %d calls", rep.DataAsOf, provider.calls)
+	}
+}
+
+func TestQueueReportWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newReportStore(t)
+	provider := &gatedAI{asked: make(chan struct{}), gate: make(chan struct{})}
+	reporter := newTestReporter(t, store, provider)
+	m := jobs.NewManager(ctx)
+
+	first := time.Now().Add(-time.Hour)
+	imported(t, store, first)
+	job, err := reporter.SubmitReport(m, "api", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// imports finishing while the job waits on the AI
+	<-provider.asked
+	second := first.Add(30 * time.Minute)
+	imported(t, store, second)
+	for range 3 {
+		if err := reporter.QueueReport(m, "import"); err != nil {
+			t.Fatalf("QueueReport: %v", err)
+		}
+	}
+
+	provider.gate <- struct{}{}
+	<-job.Done()
+	<-provider.asked // the queued run
+	provider.gate <- struct{}{}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rep, err := store.Reports.Get(ctx, 10)
+		if err == nil && rep.DataAsOf.Equal(second) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("report never caught up with the second import: %+v, %v", rep, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := provider.calls.Load(); n != 2 {
+		t.Errorf("%d AI calls, want the running job plus one queued run", n)
+	}
+}
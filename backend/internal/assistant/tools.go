@@ -0,0 +1,439 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+const (
+	maxSearchResults = 20
+	maxCompared      = 5
+	squadBudget      = 100.0 // £m
+	maxPerClub       = 3
+)
+
+// squadShape is the FPL rule for a full 15-man squad.
+var squadShape = map[string]int{"GK": 2, "DEF": 5, "MID": 5, "FWD": 3}
+
+// ToolTrace records one tool call made while answering, for the client.
+type ToolTrace struct {
+	Name     string
+	Args     json.RawMessage
+	Result   json.RawMessage // nil when Error is set
+	Error    string
+	Duration time.Duration
+}
+
+// Tools lets the model query the FPL tables itself instead of relying on
+// what fits in the prompt.
+type Tools struct {
+	store    *repository.Store
+	maxSteps int
+	deadline time.Duration
+}
+
+// NewTools allows maxSteps rounds of tool calls per question; 0 disables
+// tools entirely. deadline caps a whole Run (0 = no cap).
+func NewTools(store *repository.Store, maxSteps int, deadline time.Duration) *Tools {
+	return &Tools{store: store, maxSteps: maxSteps, deadline: deadline}
+}
+
+// Enabled reports whether Run will offer tools at all.
+func (t *Tools) Enabled() bool { return t != nil && t.maxSteps > 0 }
+
+var toolDefs = []ai.Tool{
+	{
+		Name:        "search_players",
+		Description: "Find players by position, club, price and form. Returns up to 20, best first.",
+		Parameters: object(map[string]any{
+			"position":  enum("string", "Position", "GK", "DEF", "MID", "FWD"),
+			"team":      prop("string", "Club short name, e.g. ARS"),
+			"max_price": prop("number", "Highest price in £m"),
+			"min_form":  prop("number", "Lowest form"),
+			"name":      prop("string", "Part of the player's name"),
+			"sort_by":   enum("string", "Ordering, default form", "form", "total_points", "price", "selected_by_percent", "ict_index"),
+			"limit":     prop("integer", "How many to return, max 20"),
+		}),
+	},
+	{
+		Name:        "get_player_fixtures",
+		Description: "Upcoming fixtures for a player's club with opponent, venue and difficulty (1 easy - 5 hard).",
+		Parameters: object(map[string]any{
+			"player_id": prop("integer", "Player id from search_players"),
+			"count":     prop("integer", "How many fixtures, default 5"),
+		}, "player_id"),
+	},
+	{
+		Name:        "compare_players",
+		Description: "Side-by-side stats and next fixtures for 2-5 players.",
+		Parameters: object(map[string]any{
+			"player_ids": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "description": "Player ids"},
+		}, "player_ids"),
+	},
+	{
+		Name: "check_squad",
+		Description: "Check a squad against FPL rules: 15 players (2 GK, 5 DEF, 5 MID, 3 FWD), " +
+			"at most 3 per club, and total cost within the budget. Lists every problem found.",
+		Parameters: object(map[string]any{
+			"player_ids": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "description": "All squad player ids"},
+			"budget":     prop("number", "Budget in £m, default 100"),
+		}, "player_ids"),
+	},
+}
+
+// Run answers req, letting the model call tools for up to maxSteps rounds.
+// After the last round tools are withdrawn so the model has to answer with
+// what it has.
+func (t *Tools) Run(ctx context.Context, p ai.Provider, req ai.Request) (ai.Response, []ToolTrace, error) {
+	if t.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.deadline)
+		defer cancel()
+	}
+	maxSteps := t.maxSteps
+	if maxSteps <= 0 {
+		resp, err := p.Generate(ctx, req)
+		return resp, nil, err
+	}
+
+	d, err := loadData(ctx, t.store)
+	if err != nil {
+		return ai.Response{}, nil, &StorageError{err}
+	}
+
+	var trace []ToolTrace
+	var usage ai.Usage
+	msgs := append([]ai.Message(nil), req.Messages...)
+	for step := 0; ; step++ {
+		call := ai.Request{System: req.System, Messages: msgs}
+		if step < maxSteps {
+			call.Tools = toolDefs
+		}
+		resp, err := p.Generate(ctx, call)
+		if err != nil {
+			return ai.Response{}, trace, err
+		}
+		usage.PromptTokens += resp.Usage.PromptTokens
+		usage.CompletionTokens += resp.Usage.CompletionTokens
+		if len(resp.ToolCalls) == 0 || step >= maxSteps {
+			resp.Usage = usage
+			resp.ToolCalls = nil
+			return resp, trace, nil
+		}
+
+		msgs = append(msgs, ai.Message{Role: ai.RoleAssistant, Content: resp.Text, ToolCalls: resp.ToolCalls})
+		for _, c := range resp.ToolCalls {
+			start := time.Now()
+			result, err := d.callTool(c.Name, c.Args)
+			tr := ToolTrace{Name: c.Name, Args: c.Args, Duration: time.Since(start)}
+			if err != nil {
+				// the model sees the error and can correct itself
+				tr.Error = err.Error()
+				result = map[string]string{"error": err.Error()}
+			}
+			b, _ := json.Marshal(result)
+			if err == nil {
+				tr.Result = b
+			}
+			trace = append(trace, tr)
+			msgs = append(msgs, ai.Message{Role: ai.RoleTool, Content: string(b), ToolCallID: c.ID, ToolName: c.Name})
+		}
+		log.Printf("🛠️ AI tool round %d: %d call(s)", step+1, len(resp.ToolCalls))
+	}
+}
+
+func (d *data) callTool(name string, args json.RawMessage) (any, error) {
+	switch name {
+	case "search_players":
+		var a struct {
+			Position string   `json:"position"`
+			Team     string   `json:"team"`
+			MaxPrice *float64 `json:"max_price"`
+			MinForm  *float64 `json:"min_form"`
+			Name     string   `json:"name"`
+			SortBy   string   `json:"sort_by"`
+			Limit    int      `json:"limit"`
+		}
+		if err := decodeArgs(args, &a); err != nil {
+			return nil, err
+		}
+		return d.searchPlayers(a.Position, a.Team, a.Name, a.MaxPrice, a.MinForm, a.SortBy, a.Limit)
+	case "get_player_fixtures":
+		var a struct {
+			PlayerID uint `json:"player_id"`
+			Count    int  `json:"count"`
+		}
+		if err := decodeArgs(args, &a); err != nil {
+			return nil, err
+		}
+		return d.playerFixtures(a.PlayerID, a.Count)
+	case "compare_players":
+		var a struct {
+			PlayerIDs []uint `json:"player_ids"`
+		}
+		if err := decodeArgs(args, &a); err != nil {
+			return nil, err
+		}
+		return d.comparePlayers(a.PlayerIDs)
+	case "check_squad":
+		var a struct {
+			PlayerIDs []uint   `json:"player_ids"`
+			Budget    *float64 `json:"budget"`
+		}
+		if err := decodeArgs(args, &a); err != nil {
+			return nil, err
+		}
+		budget := squadBudget
+		if a.Budget != nil {
+			budget = *a.Budget
+		}
+		return d.checkSquad(a.PlayerIDs, budget), nil
+	default:
+		return nil, fmt.Errorf("unknown tool %q", name)
+	}
+}
+
+type playerInfo struct {
+	ID                uint    `json:"id"`
+	Name              string  `json:"name"`
+	Team              string  `json:"team"`
+	Position          string  `json:"position"`
+	Price             float64 `json:"price"`
+	Form              float64 `json:"form"`
+	TotalPoints       int     `json:"total_points"`
+	EventPoints       int     `json:"event_points"`
+	IctIndex          float64 `json:"ict_index"`
+	SelectedByPercent float64 `json:"selected_by_percent"`
+	NextFixtures      string  `json:"next_fixtures"`
+}
+
+func (d *data) info(p model.Player) playerInfo {
+	return playerInfo{
+		ID: p.ID, Name: p.WebName, Team: d.teams[p.TeamID].ShortName, Position: p.Position,
+		Price: p.CurrentPrice, Form: parseFloat(p.Form), TotalPoints: p.TotalPoints, EventPoints: p.EventPoints,
+		IctIndex: parseFloat(p.IctIndex), SelectedByPercent: p.SelectedByPercent, NextFixtures: d.fixtureRun(p.TeamID),
+	}
+}
+
+func (d *data) searchPlayers(position, team, name string, maxPrice, minForm *float64, sortBy string, limit int) (any, error) {
+	if limit <= 0 || limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+	var key func(playerInfo) float64
+	switch sortBy {
+	case "", "form":
+		key = func(p playerInfo) float64 { return p.Form }
+	case "total_points":
+		key = func(p playerInfo) float64 { return float64(p.TotalPoints) }
+	case "price":
+		key = func(p playerInfo) float64 { return p.Price }
+	case "selected_by_percent":
+		key = func(p playerInfo) float64 { return p.SelectedByPercent }
+	case "ict_index":
+		key = func(p playerInfo) float64 { return p.IctIndex }
+	default:
+		return nil, fmt.Errorf("unknown sort_by %q", sortBy)
+	}
+
+	var out []playerInfo
+	for _, p := range d.players {
+		info := d.info(p)
+		switch {
+		case position != "" && !strings.EqualFold(info.Position, position),
+			team != "" && !strings.EqualFold(info.Team, team),
+			name != "" && !strings.Contains(strings.ToLower(p.FirstName+" "+p.LastName+" "+p.WebName), strings.ToLower(name)),
+			maxPrice != nil && info.Price > *maxPrice,
+			minForm != nil && info.Form < *minForm:
+			continue
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ki, kj := key(out[i]), key(out[j]); ki != kj {
+			return ki > kj
+		}
+		return out[i].ID < out[j].ID
+	})
+	total := len(out)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return map[string]any{"matches": total, "players": out}, nil
+}
+
+func (d *data) player(id uint) (model.Player, error) {
+	p, ok := d.players[id]
+	if !ok {
+		return p, fmt.Errorf("no player with id %d", id)
+	}
+	return p, nil
+}
+
+func (d *data) playerFixtures(id uint, count int) (any, error) {
+	p, err := d.player(id)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 || count > 10 {
+		count = 5
+	}
+	type fixture struct {
+		Gameweek   *int       `json:"gameweek"`
+		Kickoff    *time.Time `json:"kickoff"`
+		Opponent   string     `json:"opponent"`
+		Venue      string     `json:"venue"`
+		Difficulty int        `json:"difficulty"`
+	}
+	fixtures := []fixture{}
+	for _, f := range d.upcoming[p.TeamID] {
+		if len(fixtures) == count {
+			break
+		}
+		fx := fixture{Gameweek: f.Event, Kickoff: f.KickoffTime, Opponent: d.teams[f.TeamAID].ShortName, Venue: "H", Difficulty: f.TeamHDifficulty}
+		if f.TeamAID == p.TeamID {
+			fx.Opponent, fx.Venue, fx.Difficulty = d.teams[f.TeamHID].ShortName, "A", f.TeamADifficulty
+		}
+		fixtures = append(fixtures, fx)
+	}
+	return map[string]any{"player": d.info(p), "fixtures": fixtures}, nil
+}
+
+func (d *data) comparePlayers(ids []uint) (any, error) {
+	if len(ids) < 2 || len(ids) > maxCompared {
+		return nil, fmt.Errorf("compare 2-%d players, got %d", maxCompared, len(ids))
+	}
+	type compared struct {
+		playerInfo
+		AvgDifficulty float64 `json:"avg_difficulty_next_5"`
+		PointsPerM    float64 `json:"points_per_million"`
+	}
+	out := make([]compared, 0, len(ids))
+	for _, id := range ids {
+		p, err := d.player(id)
+		if err != nil {
+			return nil, err
+		}
+		c := compared{playerInfo: d.info(p)}
+		if p.CurrentPrice > 0 {
+			c.PointsPerM = float64(p.TotalPoints) / p.CurrentPrice
+		}
+		n, sum := 0, 0
+		for _, f := range d.upcoming[p.TeamID] {
+			if n == 5 {
+				break
+			}
+			if f.TeamHID == p.TeamID {
+				sum += f.TeamHDifficulty
+			} else {
+				sum += f.TeamADifficulty
+			}
+			n++
+		}
+		if n > 0 {
+			c.AvgDifficulty = float64(sum) / float64(n)
+		}
+		out = append(out, c)
+	}
+	return map[string]any{"players": out}, nil
+}
+
+// SquadCheck is the check_squad result.
+type SquadCheck struct {
+	Valid     bool           `json:"valid"`
+	TotalCost float64        `json:"total_cost"`
+	Budget    float64        `json:"budget"`
+	Remaining float64        `json:"remaining"`
+	Positions map[string]int `json:"positions"`
+	Problems  []string       `json:"problems"`
+}
+
+func (d *data) checkSquad(ids []uint, budget float64) SquadCheck {
+	res := SquadCheck{Budget: budget, Positions: map[string]int{}, Problems: []string{}}
+	seen := map[uint]bool{}
+	clubs := map[uint]int{}
+	for _, id := range ids {
+		if seen[id] {
+			res.Problems = append(res.Problems, fmt.Sprintf("player %d is listed twice", id))
+			continue
+		}
+		seen[id] = true
+		p, err := d.player(id)
+		if err != nil {
+			res.Problems = append(res.Problems, err.Error())
+			continue
+		}
+		res.TotalCost += p.CurrentPrice
+		res.Positions[p.Position]++
+		clubs[p.TeamID]++
+	}
+	if len(ids) != 15 {
+		res.Problems = append(res.Problems, fmt.Sprintf("a squad has 15 players, got %d", len(ids)))
+	}
+	for _, pos := range []string{"GK", "DEF", "MID", "FWD"} {
+		if got := res.Positions[pos]; got != squadShape[pos] {
+			res.Problems = append(res.Problems, fmt.Sprintf("needs %d %s, got %d", squadShape[pos], pos, got))
+		}
+	}
+	teamIDs := make([]uint, 0, len(clubs))
+	for id := range clubs {
+		teamIDs = append(teamIDs, id)
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	for _, id := range teamIDs {
+		if clubs[id] > maxPerClub {
+			res.Problems = append(res.Problems, fmt.Sprintf("%d players from %s, max %d", clubs[id], d.teams[id].ShortName, maxPerClub))
+		}
+	}
+	res.TotalCost = round1(res.TotalCost)
+	res.Remaining = round1(budget - res.TotalCost)
+	if res.Remaining < 0 {
+		res.Problems = append(res.Problems, fmt.Sprintf("£%.1fm over budget", -res.Remaining))
+	}
+	res.Valid = len(res.Problems) == 0
+	return res
+}
+
+func decodeArgs(raw json.RawMessage, dst any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("argument %q should be %s", typeErr.Field, typeErr.Type)
+		}
+		return fmt.Errorf("arguments are not valid JSON: %v", err)
+	}
+	return nil
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+func object(props map[string]any, required ...string) map[string]any {
+	o := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+func prop(typ, desc string) map[string]any {
+	return map[string]any{"type": typ, "description": desc}
+}
+
+func enum(typ, desc string, values ...string) map[string]any {
+	return map[string]any{"type": typ, "description": desc, "enum": values}
+}
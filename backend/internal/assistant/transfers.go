@@ -0,0 +1,298 @@
+package assistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+const (
+	MaxTransfers       = 5
+	candidatesPerPos   = 8
+	suggestionAttempts = 3
+)
+
+// suggestionSchema is what the model must return. Both id and name are asked
+// for so a wrong id can still be caught by a name mismatch.
+var suggestionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"transfers": map[string]any{
+			"type": "array",
+			"items": object(map[string]any{
+				"out_id":   prop("integer", "Id of the player to sell"),
+				"out_name": prop("string", "Web name of the player to sell"),
+				"in_id":    prop("integer", "Id of the player to buy"),
+				"in_name":  prop("string", "Web name of the player to buy"),
+			}, "out_id", "out_name", "in_id", "in_name"),
+		},
+		"captain_id":   prop("integer", "Id of the suggested captain"),
+		"captain_name": prop("string", "Web name of the suggested captain"),
+		"reasoning":    prop("string", "Why, in a few sentences"),
+	},
+	"required": []string{"transfers", "reasoning"},
+}
+
+type rawSuggestion struct {
+	Transfers []struct {
+		OutID   uint   `json:"out_id"`
+		OutName string `json:"out_name"`
+		InID    uint   `json:"in_id"`
+		InName  string `json:"in_name"`
+	} `json:"transfers"`
+	CaptainID   uint   `json:"captain_id"`
+	CaptainName string `json:"captain_name"`
+	Reasoning   string `json:"reasoning"`
+}
+
+type Transfer struct {
+	Out model.Player
+	In  model.Player
+}
+
+// Suggestion is a validated answer: every player exists and the resulting
+// squad respects budget and club limits.
+type Suggestion struct {
+	Transfers  []Transfer
+	Captain    *model.Player
+	Reasoning  string
+	BudgetLeft float64 // £m after the transfers
+	PointsHit  int     // 4 per transfer beyond the free ones
+	Attempts   int
+	Model      string
+	Usage      ai.Usage // summed over attempts
+	Prompt     string   // template versions
+}
+
+// InvalidSuggestionError means the model kept breaking the rules; Problems
+// are from the last attempt.
+type InvalidSuggestionError struct {
+	Attempts int
+	Problems []string
+	Usage    ai.Usage
+	Prompt   string
+}
+
+func (e *InvalidSuggestionError) Error() string {
+	return fmt.Sprintf("no valid suggestion after %d attempts: %s", e.Attempts, strings.Join(e.Problems, "; "))
+}
+
+// Advisor asks the model for structured transfer suggestions and only lets
+// through ones that check out against the database.
+type Advisor struct {
+	store    *repository.Store
+	ai       ai.Provider
+	prompts  *Builder
+	deadline time.Duration
+}
+
+// NewAdvisor caps each Suggest, retries included, at deadline (0 = no cap).
+func NewAdvisor(store *repository.Store, provider ai.Provider, prompts *Builder, deadline time.Duration) *Advisor {
+	return &Advisor{store: store, ai: provider, prompts: prompts, deadline: deadline}
+}
+
+// Suggest asks up to three times, feeding the problems of each rejected
+// answer back to the model.
+func (a *Advisor) Suggest(ctx context.Context, squad Squad, freeTransfers int, notes string) (Suggestion, error) {
+	if a.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.deadline)
+		defer cancel()
+	}
+	d, err := loadData(ctx, a.store)
+	if err != nil {
+		return Suggestion{}, &StorageError{err}
+	}
+
+	v := d.view(&squad, time.Now(), false)
+	v.Candidates = d.candidates(squad)
+	v.FreeTransfers = freeTransfers
+	system, err := a.prompts.compose(v, "persona", "squad", "transfers")
+	if err != nil {
+		return Suggestion{}, &StorageError{err}
+	}
+	ask := "Suggest my transfers and captain."
+	if notes = strings.TrimSpace(notes); notes != "" {
+		ask += "\n" + notes
+	}
+	msgs := []ai.Message{{Role: ai.RoleUser, Content: ask}}
+
+	var problems []string
+	var usage ai.Usage
+	for attempt := 1; attempt <= suggestionAttempts; attempt++ {
+		resp, err := a.ai.Generate(ctx, ai.Request{System: system.Text, Messages: msgs, Schema: suggestionSchema})
+		if err != nil {
+			return Suggestion{}, err
+		}
+		usage.PromptTokens += resp.Usage.PromptTokens
+		usage.CompletionTokens += resp.Usage.CompletionTokens
+
+		var raw rawSuggestion
+		if err := json.Unmarshal([]byte(stripFence(resp.Text)), &raw); err != nil {
+			problems = []string{"the reply was not valid JSON for the schema: " + err.Error()}
+		} else {
+			var s Suggestion
+			s, problems = d.validate(raw, squad, freeTransfers)
+			if len(problems) == 0 {
+				s.Attempts, s.Model, s.Usage, s.Prompt = attempt, resp.Model, usage, system.Version
+				return s, nil
+			}
+		}
+
+		log.Printf("⚠️ transfer suggestion attempt %d rejected: %s", attempt, strings.Join(problems, "; "))
+		msgs = append(msgs,
+			ai.Message{Role: ai.RoleAssistant, Content: resp.Text},
+			ai.Message{Role: ai.RoleUser, Content: "That suggestion breaks the rules:\n- " + strings.Join(problems, "\n- ") +
+				"\nFix it and answer with the full JSON again."},
+		)
+	}
+	return Suggestion{}, &InvalidSuggestionError{Attempts: suggestionAttempts, Problems: problems, Usage: usage, Prompt: system.Version}
+}
+
+// candidates lists in-form players per position that aren't in the squad,
+// so the model has real ids to pick from.
+func (d *data) candidates(squad Squad) []positionView {
+	owned := map[uint]bool{}
+	for _, id := range append(append([]uint{}, squad.Starters...), squad.Bench...) {
+		owned[id] = true
+	}
+	var out []positionView
+	for _, pos := range []string{"GK", "DEF", "MID", "FWD"} {
+		ps := d.topForm(candidatesPerPos, func(p model.Player) bool { return p.Position == pos && !owned[p.ID] })
+		out = append(out, positionView{Position: pos, Players: d.playerViews(ps)})
+	}
+	return out
+}
+
+func (d *data) validate(raw rawSuggestion, squad Squad, freeTransfers int) (Suggestion, []string) {
+	var problems []string
+	add := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+
+	inSquad := map[uint]bool{}
+	for _, id := range append(append([]uint{}, squad.Starters...), squad.Bench...) {
+		inSquad[id] = true
+	}
+
+	s := Suggestion{Reasoning: strings.TrimSpace(raw.Reasoning), BudgetLeft: squad.BudgetLeft}
+	if len(raw.Transfers) > MaxTransfers {
+		add("at most %d transfers, got %d", MaxTransfers, len(raw.Transfers))
+	}
+	for i, t := range raw.Transfers {
+		out, err := d.resolve(t.OutID, t.OutName)
+		if err != nil {
+			add("transfer %d out: %v", i+1, err)
+			continue
+		}
+		in, err := d.resolve(t.InID, t.InName)
+		if err != nil {
+			add("transfer %d in: %v", i+1, err)
+			continue
+		}
+		if !inSquad[out.ID] {
+			add("%s (id %d) is not in the squad, so can't be sold", out.WebName, out.ID)
+			continue
+		}
+		if inSquad[in.ID] {
+			add("%s (id %d) is already in the squad", in.WebName, in.ID)
+			continue
+		}
+		if out.Position != in.Position {
+			add("%s is a %s but %s is a %s; swap like for like", out.WebName, out.Position, in.WebName, in.Position)
+		}
+		delete(inSquad, out.ID)
+		inSquad[in.ID] = true
+		s.BudgetLeft += out.CurrentPrice - in.CurrentPrice
+		s.Transfers = append(s.Transfers, Transfer{Out: out, In: in})
+	}
+
+	s.BudgetLeft = round1(s.BudgetLeft)
+	if s.BudgetLeft < 0 {
+		add("the transfers are £%.1fm over budget", -s.BudgetLeft)
+	}
+	clubs := map[uint]int{}
+	for id := range inSquad {
+		if p, ok := d.players[id]; ok {
+			clubs[p.TeamID]++
+		}
+	}
+	teamIDs := make([]uint, 0, len(clubs))
+	for id := range clubs {
+		teamIDs = append(teamIDs, id)
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	for _, id := range teamIDs {
+		if clubs[id] > maxPerClub {
+			add("the squad would have %d players from %s, max %d", clubs[id], d.teams[id].ShortName, maxPerClub)
+		}
+	}
+
+	if raw.CaptainID != 0 || raw.CaptainName != "" {
+		c, err := d.resolve(raw.CaptainID, raw.CaptainName)
+		switch {
+		case err != nil:
+			add("captain: %v", err)
+		case !inSquad[c.ID]:
+			add("captain %s is not in the squad after the transfers", c.WebName)
+		default:
+			s.Captain = &c
+		}
+	}
+	if s.Reasoning == "" {
+		add("reasoning is empty")
+	}
+	if extra := len(s.Transfers) - freeTransfers; extra > 0 {
+		s.PointsHit = 4 * extra
+	}
+	return s, problems
+}
+
+// resolve finds a player by id, falling back to an exact web name match.
+// When both are given they must agree.
+func (d *data) resolve(id uint, name string) (model.Player, error) {
+	name = strings.TrimSpace(name)
+	if p, ok := d.players[id]; ok {
+		if name != "" && !strings.EqualFold(p.WebName, name) {
+			return p, fmt.Errorf("id %d is %s, not %s", id, p.WebName, name)
+		}
+		return p, nil
+	}
+	if name == "" {
+		return model.Player{}, fmt.Errorf("no player with id %d", id)
+	}
+	var found []model.Player
+	for _, p := range d.players {
+		if strings.EqualFold(p.WebName, name) {
+			found = append(found, p)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return model.Player{}, fmt.Errorf("no player called %q", name)
+	case 1:
+		return found[0], nil
+	default:
+		return model.Player{}, fmt.Errorf("%d players are called %q; give the id", len(found), name)
+	}
+}
+
+// stripFence drops a ```json (or ```markdown) fence some models wrap their
+// whole answer in.
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "```"), "```")
+	if lang, rest, ok := strings.Cut(s, "\n"); ok && !strings.ContainsAny(lang, "{[ ") {
+		s = rest
+	}
+	return strings.TrimSpace(s)
+}
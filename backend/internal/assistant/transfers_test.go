@@ -0,0 +1,73 @@
+package assistant
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+)
+
+func testData() *data {
+	d := &data{
+		players: map[uint]model.Player{},
+		teams:   map[uint]model.Team{1: {ID: 1, ShortName: "ARS"}, 2: {ID: 2, ShortName: "LIV"}, 3: {ID: 3, ShortName: "BRE"}},
+	}
+	for _, p := range []model.Player{
+		{ID: 1, WebName: "Saliba", TeamID: 1, Position: "DEF", CurrentPrice: 6.0},
+		{ID: 2, WebName: "Saka", TeamID: 1, Position: "MID", CurrentPrice: 10.0},
+		{ID: 3, WebName: "Rice", TeamID: 1, Position: "MID", CurrentPrice: 6.5},
+		{ID: 4, WebName: "Salah", TeamID: 2, Position: "MID", CurrentPrice: 13.0},
+		{ID: 5, WebName: "Gakpo", TeamID: 2, Position: "MID", CurrentPrice: 7.5},
+		{ID: 6, WebName: "Odegaard", TeamID: 1, Position: "MID", CurrentPrice: 8.5},
+		{ID: 7, WebName: "Jota", TeamID: 2, Position: "FWD", CurrentPrice: 7.5},
+		{ID: 8, WebName: "Mbeumo", TeamID: 3, Position: "MID", CurrentPrice: 7.0},
+	} {
+		d.players[p.ID] = p
+	}
+	return d
+}
+
+func TestValidateSuggestion(t *testing.T) {
+	d := testData()
+	squad := Squad{Starters: []uint{1, 2, 3, 5}, BudgetLeft: 2.0}
+
+	tests := []struct {
+		name    string
+		raw     string
+		free    int
+		problem string // substring of the only problem; "" means valid
+		budget  float64
+		hit     int
+	}{
+		{"no moves", `{"transfers": [], "reasoning": "hold"}`, 1, "", 2.0, 0},
+		{"by id", `{"transfers": [{"out_id": 5, "in_id": 4}], "captain_id": 4, "reasoning": "r"}`, 1, "over budget", -3.5, 0},
+		{"by name", `{"transfers": [{"out_name": "gakpo", "in_name": "Salah"}], "reasoning": "r"}`, 1, "over budget", -3.5, 0},
+		{"affordable", `{"transfers": [{"out_id": 2, "in_id": 8}], "captain_id": 8, "reasoning": "r"}`, 1, "", 5.0, 0},
+		{"id and name disagree", `{"transfers": [{"out_id": 5, "out_name": "Saka", "in_id": 4}], "reasoning": "r"}`, 1, "id 5 is Gakpo, not Saka", 2.0, 0},
+		{"not owned", `{"transfers": [{"out_id": 4, "in_id": 6}], "reasoning": "r"}`, 1, "not in the squad", 2.0, 0},
+		{"already owned", `{"transfers": [{"out_id": 5, "in_id": 2}], "reasoning": "r"}`, 1, "already in the squad", 2.0, 0},
+		{"position swap", `{"transfers": [{"out_id": 5, "in_id": 7}], "reasoning": "r"}`, 1, "like for like", 2.0, 0},
+		{"club limit", `{"transfers": [{"out_id": 5, "in_id": 6}], "reasoning": "r"}`, 1, "4 players from ARS", 1.0, 0},
+		{"captain sold", `{"transfers": [{"out_id": 2, "in_id": 8}], "captain_id": 2, "reasoning": "r"}`, 1, "captain Saka is not in the squad", 5.0, 0},
+		{"no reasoning", `{"transfers": []}`, 1, "reasoning is empty", 2.0, 0},
+		{"points hit", `{"transfers": [{"out_id": 2, "in_id": 6}, {"out_id": 3, "in_id": 8}], "reasoning": "r"}`, 1, "", 3.0, 4},
+		{"free transfers", `{"transfers": [{"out_id": 2, "in_id": 6}, {"out_id": 3, "in_id": 8}], "reasoning": "r"}`, 2, "", 3.0, 0},
+	}
+	for _, tt := range tests {
+		var raw rawSuggestion
+		if err := json.Unmarshal([]byte(tt.raw), &raw); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		s, problems := d.validate(raw, squad, tt.free)
+		switch {
+		case tt.problem == "" && len(problems) > 0:
+			t.Errorf("%s: unexpected problems %q", tt.name, problems)
+		case tt.problem != "" && (len(problems) != 1 || !strings.Contains(problems[0], tt.problem)):
+			t.Errorf("%s: problems %q, want one about %q", tt.name, problems, tt.problem)
+		}
+		if s.BudgetLeft != tt.budget || s.PointsHit != tt.hit {
+			t.Errorf("%s: budget %.1f hit %d, want %.1f and %d", tt.name, s.BudgetLeft, s.PointsHit, tt.budget, tt.hit)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package assistant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// Caller is who an AI request is counted against: a signed-in user, or the
+// client IP when UserID is zero.
+type Caller struct {
+	UserID uint
+	Client string
+}
+
+// QuotaError means the caller used up today's requests.
+type QuotaError struct {
+	Limit   int
+	ResetAt time.Time // next UTC midnight
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("daily AI quota of %d requests used up", e.Limit)
+}
+
+// Answer is what the cache holds: the reply and the tool calls behind it.
+type Answer struct {
+	Response ai.Response
+	Tools    []ToolTrace
+	Prompt   string // template versions
+}
+
+type cacheEntry struct {
+	answer  Answer
+	expires time.Time
+}
+
+// Meter caches identical questions, enforces the daily quota and records
+// token usage. A nil Meter does none of that.
+type Meter struct {
+	store    *repository.Store
+	provider string
+	quota    int           // uncached requests per caller per UTC day, 0 = unlimited
+	ttl      time.Duration // 0 = no cache
+	size     int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewMeter(store *repository.Store, provider string, dailyQuota int, cacheTTL time.Duration, cacheSize int) *Meter {
+	return &Meter{
+		store:    store,
+		provider: provider,
+		quota:    dailyQuota,
+		ttl:      cacheTTL,
+		size:     cacheSize,
+		entries:  map[string]cacheEntry{},
+	}
+}
+
+// DailyQuota is 0 when unlimited.
+func (m *Meter) DailyQuota() int {
+	if m == nil {
+		return 0
+	}
+	return m.quota
+}
+
+// Lookup returns a cached answer for req, plus the key to Store it under on
+// a miss. The key covers the whole prompt with the question normalized, and
+// the time of the last FPL import, so new data never gets a stale answer.
+func (m *Meter) Lookup(ctx context.Context, req ai.Request) (Answer, string, bool) {
+	if m == nil || m.ttl <= 0 {
+		return Answer{}, "", false
+	}
+	version, err := m.store.FetchStates.LastFetchedAt(ctx)
+	if err != nil {
+		log.Printf("⚠️ AI cache disabled for this request, can't read data version: %v", err)
+		return Answer{}, "", false
+	}
+	key := cacheKey(version, req)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return Answer{}, key, false
+	}
+	return e.answer, key, true
+}
+
+// Store caches a for key; an empty key (cache off or unavailable) is ignored.
+func (m *Meter) Store(key string, a Answer) {
+	if m == nil || key == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if len(m.entries) >= m.size {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	if len(m.entries) >= m.size {
+		// still full: drop whatever expires first
+		var oldest string
+		for k, e := range m.entries {
+			if oldest == "" || e.expires.Before(m.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(m.entries, oldest)
+	}
+	m.entries[key] = cacheEntry{answer: a, expires: now.Add(m.ttl)}
+}
+
+func cacheKey(version time.Time, req ai.Request) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00", version.UnixNano(), req.System)
+	for _, msg := range req.Messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", msg.Role, normalize(msg.Content))
+	}
+	for _, t := range req.Tools {
+		fmt.Fprintf(h, "tool:%s\x00", t.Name)
+	}
+	if req.Schema != nil {
+		schema, _ := json.Marshal(req.Schema)
+		h.Write(schema)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalize makes "Who should I  captain?" and "who should i captain" the
+// same question.
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Allow checks the caller's quota for today (UTC).
+func (m *Meter) Allow(ctx context.Context, c Caller) error {
+	if m == nil || m.quota <= 0 {
+		return nil
+	}
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	used, err := m.store.AIUsage.CountUncached(ctx, c.UserID, c.Client, day)
+	if err != nil {
+		return &StorageError{fmt.Errorf("❌ failed to count AI usage: %w", err)}
+	}
+	if used >= m.quota {
+		return &QuotaError{Limit: m.quota, ResetAt: day.Add(24 * time.Hour)}
+	}
+	return nil
+}
+
+// Record stores one answered request. Failing to record shouldn't cost the
+// user their answer, so errors are only logged.
+func (m *Meter) Record(ctx context.Context, c Caller, endpoint, promptVersion string, resp ai.Response, cached bool) {
+	if m == nil {
+		return
+	}
+	u := model.AIUsage{
+		Client:        c.Client,
+		Endpoint:      endpoint,
+		Provider:      m.provider,
+		Model:         resp.Model,
+		PromptVersion: promptVersion,
+		Cached:        cached,
+	}
+	if c.UserID != 0 {
+		u.UserID = &c.UserID
+	}
+	if !cached {
+		u.PromptTokens, u.CompletionTokens = resp.Usage.PromptTokens, resp.Usage.CompletionTokens
+	}
+	// the request may already be cancelled; the row is still worth keeping
+	if err := m.store.AIUsage.Record(context.WithoutCancel(ctx), &u); err != nil {
+		log.Printf("⚠️ failed to record AI usage: %v", err)
+	}
+}
+
+// UsageTotals adds up a group of AIUsage rows.
+type UsageTotals struct {
+	Key              string
+	Requests         int
+	Cached           int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+func (t *UsageTotals) add(u model.AIUsage) {
+	t.Requests++
+	if u.Cached {
+		t.Cached++
+	}
+	t.PromptTokens += u.PromptTokens
+	t.CompletionTokens += u.CompletionTokens
+}
+
+type UsageReport struct {
+	Total      UsageTotals
+	ByDay      []UsageTotals // key "2006-01-02" (UTC), oldest first
+	ByEndpoint []UsageTotals
+	ByModel    []UsageTotals
+	ByPrompt   []UsageTotals // key is the prompt version
+	ByCaller   []UsageTotals // key "user:<id>" or "client:<ip>", heaviest first
+}
+
+// Summarize groups usage rows for the admin report.
+func Summarize(rows []model.AIUsage) UsageReport {
+	days, endpoints, models, versions, callers := map[string]*UsageTotals{}, map[string]*UsageTotals{},
+		map[string]*UsageTotals{}, map[string]*UsageTotals{}, map[string]*UsageTotals{}
+	group := func(m map[string]*UsageTotals, key string, u model.AIUsage) {
+		t, ok := m[key]
+		if !ok {
+			t = &UsageTotals{Key: key}
+			m[key] = t
+		}
+		t.add(u)
+	}
+
+	var r UsageReport
+	for _, u := range rows {
+		r.Total.add(u)
+		group(days, u.CreatedAt.UTC().Format("2006-01-02"), u)
+		group(endpoints, u.Endpoint, u)
+		group(models, u.Model, u)
+		group(versions, u.PromptVersion, u)
+		if u.UserID != nil {
+			group(callers, fmt.Sprintf("user:%d", *u.UserID), u)
+		} else {
+			group(callers, "client:"+u.Client, u)
+		}
+	}
+	r.ByDay = sortedTotals(days, func(a, b UsageTotals) bool { return a.Key < b.Key })
+	heaviest := func(a, b UsageTotals) bool {
+		ta, tb := a.PromptTokens+a.CompletionTokens, b.PromptTokens+b.CompletionTokens
+		if ta != tb {
+			return ta > tb
+		}
+		return a.Key < b.Key
+	}
+	r.ByEndpoint = sortedTotals(endpoints, heaviest)
+	r.ByModel = sortedTotals(models, heaviest)
+	r.ByPrompt = sortedTotals(versions, heaviest)
+	r.ByCaller = sortedTotals(callers, heaviest)
+	return r
+}
+
+func sortedTotals(m map[string]*UsageTotals, less func(a, b UsageTotals) bool) []UsageTotals {
+	out := make([]UsageTotals, 0, len(m))
+	for _, t := range m {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment overrides, applied on top of the TOML file:
+//
+//	SERVER_ADDR / PORT                          [server] address
+//	SERVER_TLS_CERT, SERVER_TLS_KEY             [server] tls_cert, tls_key
+//	SERVER_MAX_BODY_BYTES                       [server] max_body_bytes
+//	SERVER_READ_HEADER_TIMEOUT, SERVER_READ_TIMEOUT,
+//	SERVER_WRITE_TIMEOUT, SERVER_IDLE_TIMEOUT,
+//	SERVER_SHUTDOWN_TIMEOUT                     [server] *_timeout, as Go durations ("30s")
+//	STATIC_DIR                                  [server] static_dir
+//	DATABASE_URL                                full Postgres URL (implies DB_TYPE=postgres)
+//	DB_TYPE, DB_DATABASE, DB_AUTO_MIGRATE       [database] type, database, auto_migrate
+//	DB_HOST, DB_PORT, DB_USER, DB_PASS,
+//	DB_NAME, DB_SSL                             [database] host, port, user, pass, name, sslmode
+//	AI_PROVIDER, AI_MODEL, AI_BASE_URL          [ai] provider, model, base_url
+//	AI_API_KEY                                  [ai] api_key; falls back to GEMINI_API_KEY
+//	                                            or OPENAI_API_KEY for the matching provider
+//	AI_DAILY_QUOTA                              [ai] daily_quota
+//	AI_PROMPTS_DIR                              [ai] prompts_dir
+//	AUTH_SECURE_COOKIES                         [auth] secure_cookies
+//	ADMIN_API_KEY                               extra [[auth.api_keys]] entry named "env" with role admin
+func applyEnv(cfg *Config) []string {
+	var problems []string
+
+	envString("SERVER_ADDR", &cfg.Server.Address)
+	if port, ok := os.LookupEnv("PORT"); ok && os.Getenv("SERVER_ADDR") == "" {
+		cfg.Server.Address = ":" + port
+	}
+	envString("SERVER_TLS_CERT", &cfg.Server.TLSCert)
+	envString("SERVER_TLS_KEY", &cfg.Server.TLSKey)
+	envString("STATIC_DIR", &cfg.Server.StaticDir)
+	if v, ok := os.LookupEnv("SERVER_MAX_BODY_BYTES"); ok && v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SERVER_MAX_BODY_BYTES: %q is not a number", v))
+		} else {
+			cfg.Server.MaxBodyBytes = n
+		}
+	}
+	timeouts := []struct {
+		key string
+		dst *time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if err := envDuration(t.key, t.dst); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	db := &cfg.Database
+	envString("DB_TYPE", &db.Type)
+	envString("DB_DATABASE", &db.Database)
+	envString("DB_HOST", &db.DBHost)
+	envString("DB_USER", &db.DBUser)
+	envString("DB_PASS", &db.DBPass)
+	envString("DB_NAME", &db.DBName)
+	envString("DB_SSL", &db.DBSSL)
+	if err := envInt("DB_PORT", &db.DBPort); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := envBool("DB_AUTO_MIGRATE", &db.AutoMigrate); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if v, ok := os.LookupEnv("DATABASE_URL"); ok && v != "" {
+		db.URL = v
+		if _, set := os.LookupEnv("DB_TYPE"); !set {
+			db.Type = "postgres"
+		}
+	}
+	if db.URL != "" {
+		if err := db.applyURL(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	envString("AI_PROVIDER", &cfg.AI.Provider)
+	envString("AI_MODEL", &cfg.AI.Model)
+	envString("AI_BASE_URL", &cfg.AI.BaseURL)
+	envString("AI_API_KEY", &cfg.AI.APIKey)
+	envString("AI_PROMPTS_DIR", &cfg.AI.PromptsDir)
+	if err := envInt("AI_DAILY_QUOTA", &cfg.AI.DailyQuota); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.AI.APIKey == "" {
+		switch cfg.AI.Provider {
+		case "gemini":
+			cfg.AI.APIKey = os.Getenv("GEMINI_API_KEY")
+		case "openai":
+			cfg.AI.APIKey = os.Getenv("OPENAI_API_KEY")
+		}
+	}
+
+	if err := envBool("AUTH_SECURE_COOKIES", &cfg.Auth.SecureCookies); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if key := os.Getenv("ADMIN_API_KEY"); key != "" {
+		cfg.Auth.APIKeys = append(cfg.Auth.APIKeys, APIKeyConfig{Name: "env", Key: key, Roles: []string{"admin"}})
+	}
+
+	return problems
+}
+
+// applyURL copies the parts of URL into the individual fields so logging and
+// validation see the same values the driver will use.
+func (d *DatabaseConfig) applyURL() error {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return fmt.Errorf("database.url: %v", err)
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return fmt.Errorf("database.url: scheme must be postgres://, got %q", u.Scheme)
+	}
+
+	d.DBHost = u.Hostname()
+	if p := u.Port(); p != "" {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return fmt.Errorf("database.url: invalid port %q", p)
+		}
+		d.DBPort = port
+	}
+	if u.User != nil {
+		d.DBUser = u.User.Username()
+		d.DBPass, _ = u.User.Password()
+	}
+	d.DBName = strings.TrimPrefix(u.Path, "/")
+	// Without sslmode GORM's pgx would use prefer while the ping and the
+	// migrator (lib/pq) use require, so pin database.sslmode into the URL
+	// and every connection agrees.
+	q := u.Query()
+	if ssl := q.Get("sslmode"); ssl != "" {
+		d.DBSSL = ssl
+	} else {
+		q.Set("sslmode", d.DBSSL)
+		u.RawQuery = q.Encode()
+		d.URL = u.String()
+	}
+	return nil
+}
+
+func envString(key string, dst *string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = v
+	}
+}
+
+func envInt(key string, dst *int) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a number", key, v)
+	}
+	*dst = n
+	return nil
+}
+
+func envDuration(key string, dst *time.Duration) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a duration like \"30s\"", key, v)
+	}
+	*dst = d
+	return nil
+}
+
+func envBool(key string, dst *bool) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a boolean", key, v)
+	}
+	*dst = b
+	return nil
+}
+
+// ValidationError lists every invalid or missing setting at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "❌ invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Address == "" {
+		add("server.address (SERVER_ADDR) is required")
+	} else if _, _, err := net.SplitHostPort(c.Server.Address); err != nil {
+		add("server.address (SERVER_ADDR): %v", err)
+	}
+	if (c.Server.TLSCert == "") != (c.Server.TLSKey == "") {
+		add("server.tls_cert and server.tls_key must be set together")
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		add("server.max_body_bytes must be positive")
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.ReadHeaderTimeout < 0 || c.Server.WriteTimeout < 0 ||
+		c.Server.IdleTimeout < 0 || c.Server.ShutdownTimeout < 0 {
+		add("server timeouts must not be negative")
+	}
+
+	db := c.Database
+	switch db.Type {
+	case "sqlite":
+		if db.Database == "" {
+			add("database.database (DB_DATABASE) is required for sqlite")
+		}
+	case "postgres":
+		if db.DBHost == "" {
+			add("database.host (DB_HOST) is required for postgres")
+		}
+		if db.DBPort < 1 || db.DBPort > 65535 {
+			add("database.port (DB_PORT) must be 1-65535, got %d", db.DBPort)
+		}
+		if db.DBUser == "" {
+			add("database.user (DB_USER) is required for postgres")
+		}
+		if db.DBName == "" {
+			add("database.name (DB_NAME) is required for postgres")
+		}
+		switch db.DBSSL {
+		// lib/pq (ping, migrations) has no allow/prefer, unlike pgx (GORM)
+		case "disable", "require", "verify-ca", "verify-full":
+		default:
+			add("database.sslmode (DB_SSL) must be disable, require, verify-ca or verify-full, got %q", db.DBSSL)
+		}
+	case "":
+		add("database.type (DB_TYPE) is required")
+	default:
+		add("database.type (DB_TYPE) must be sqlite or postgres, got %q", db.Type)
+	}
+
+	names := map[string]bool{}
+	for i, k := range c.Auth.APIKeys {
+		switch {
+		case k.Name == "":
+			add("auth.api_keys[%d].name is required", i)
+		case names[k.Name]:
+			add("auth.api_keys: duplicate name %q", k.Name)
+		}
+		names[k.Name] = true
+		if len(k.Key) < 16 {
+			add("auth.api_keys[%d] (%s): key must be at least 16 characters", i, k.Name)
+		}
+		if len(k.Roles) == 0 {
+			add("auth.api_keys[%d] (%s): roles must not be empty", i, k.Name)
+		}
+		for _, role := range k.Roles {
+			if role != "viewer" && role != "admin" {
+				add("auth.api_keys[%d] (%s): unknown role %q (viewer, admin)", i, k.Name, role)
+			}
+		}
+	}
+
+	if c.Auth.SessionTTL < time.Minute {
+		add("auth.session_ttl must be at least 1m")
+	}
+
+	a := c.AI
+	switch a.Provider {
+	case "gemini", "openai", "fake":
+	default:
+		add("ai.provider (AI_PROVIDER) must be gemini, openai or fake, got %q", a.Provider)
+	}
+	if a.Provider != "fake" && a.Model == "" {
+		add("ai.model (AI_MODEL) is required")
+	}
+	if a.BaseURL != "" {
+		if u, err := url.Parse(a.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			add("ai.base_url (AI_BASE_URL) must be an http(s) URL")
+		}
+	}
+	if a.Temperature < 0 || a.Temperature > 2 {
+		add("ai.temperature must be 0-2")
+	}
+	if a.MaxTokens < 1 {
+		add("ai.max_tokens must be positive")
+	}
+	if a.Timeout <= 0 {
+		add("ai.timeout must be positive")
+	}
+	if a.MaxRetries < 0 || a.MaxRetries > 5 {
+		add("ai.max_retries must be 0-5")
+	}
+	if a.MaxInputChars < 100 {
+		add("ai.max_input_chars must be at least 100")
+	}
+	if a.HistoryTokens < 200 {
+		add("ai.history_tokens must be at least 200")
+	}
+	if a.MaxToolSteps < 0 || a.MaxToolSteps > 10 {
+		add("ai.max_tool_steps must be 0-10")
+	}
+	if a.CacheTTL < 0 {
+		add("ai.cache_ttl must not be negative")
+	}
+	if a.CacheTTL > 0 && a.CacheEntries < 1 {
+		add("ai.cache_entries must be positive when ai.cache_ttl is set")
+	}
+	if a.DailyQuota < 0 {
+		add("ai.daily_quota (AI_DAILY_QUOTA) must not be negative")
+	}
+
+	if c.Importer.KeepSnapshots < 0 {
+		add("importer.keep_snapshots must not be negative (0 keeps all)")
+	}
+
+	s := c.Sync
+	if s.NightStartHour < 0 || s.NightStartHour > 23 || s.NightEndHour < 0 || s.NightEndHour > 23 {
+		add("sync.night_start_hour/night_end_hour must be 0-23")
+	}
+
+	return problems
+}
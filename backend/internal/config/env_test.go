@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerTimeoutsFromEnv(t *testing.T) {
+	t.Setenv("SERVER_WRITE_TIMEOUT", "90s")
+	t.Setenv("SERVER_IDLE_TIMEOUT", "5m")
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+
+	cfg := defaults()
+	problems := applyEnv(cfg)
+	if cfg.Server.WriteTimeout != 90*time.Second || cfg.Server.IdleTimeout != 5*time.Minute {
+		t.Errorf("write %s, idle %s; want 1m30s and 5m", cfg.Server.WriteTimeout, cfg.Server.IdleTimeout)
+	}
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "SERVER_READ_TIMEOUT") {
+		t.Errorf("problems %q, want one about SERVER_READ_TIMEOUT", problems)
+	}
+}
+
+func TestApplyURLPinsSSLMode(t *testing.T) {
+	d := DatabaseConfig{URL: "postgres://u:p@db:5433/fpl?application_name=x", DBSSL: "require"}
+	if err := d.applyURL(); err != nil {
+		t.Fatal(err)
+	}
+	if d.URL != "postgres://u:p@db:5433/fpl?application_name=x&sslmode=require" || d.DBPort != 5433 || d.DBName != "fpl" {
+		t.Errorf("got %+v", d)
+	}
+
+	d = DatabaseConfig{URL: "postgres://u:p@db/fpl?sslmode=verify-full", DBSSL: "disable"}
+	if err := d.applyURL(); err != nil {
+		t.Fatal(err)
+	}
+	if d.DBSSL != "verify-full" || d.URL != "postgres://u:p@db/fpl?sslmode=verify-full" {
+		t.Errorf("an explicit sslmode should win, got %+v", d)
+	}
+}
@@ -0,0 +1,132 @@
+package fplimporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+)
+
+func (im *Importer) loadTeamNames(ctx context.Context) (map[uint]string, error) {
+	teams, err := im.store.Teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.ShortName
+	}
+	return names, nil
+}
+
+func teamName(names map[uint]string, id uint) string {
+	if n, ok := names[id]; ok && n != "" {
+		return n
+	}
+	return fmt.Sprintf("team %d", id)
+}
+
+func newPlayerEvent(p model.Player, teams map[uint]string, now time.Time) model.ChangeEvent {
+	return model.ChangeEvent{
+		Kind:       model.ChangePlayerAdded,
+		EntityType: "player",
+		EntityID:   p.ID,
+		NewValue:   p.Position,
+		Summary: fmt.Sprintf("%s (%s, %s) added at £%.1fm",
+			p.WebName, teamName(teams, p.TeamID), p.Position, p.CurrentPrice),
+		CreatedAt: now,
+	}
+}
+
+func playerChanges(old, cur model.Player, teams map[uint]string, now time.Time) []model.ChangeEvent {
+	var out []model.ChangeEvent
+	ev := func(kind, oldV, newV, summary string) {
+		out = append(out, model.ChangeEvent{
+			Kind:       kind,
+			EntityType: "player",
+			EntityID:   cur.ID,
+			OldValue:   oldV,
+			NewValue:   newV,
+			Summary:    summary,
+			CreatedAt:  now,
+		})
+	}
+
+	if cur.CurrentPrice != old.CurrentPrice {
+		kind := model.ChangePriceRise
+		if cur.CurrentPrice < old.CurrentPrice {
+			kind = model.ChangePriceFall
+		}
+		ev(kind, price(old.CurrentPrice), price(cur.CurrentPrice),
+			fmt.Sprintf("%s £%.1fm → £%.1fm", cur.WebName, old.CurrentPrice, cur.CurrentPrice))
+	}
+	if cur.TeamID != old.TeamID {
+		from, to := teamName(teams, old.TeamID), teamName(teams, cur.TeamID)
+		ev(model.ChangeTeamTransfer, strconv.Itoa(int(old.TeamID)), strconv.Itoa(int(cur.TeamID)),
+			fmt.Sprintf("%s moved from %s to %s", cur.WebName, from, to))
+	}
+	if cur.Position != old.Position {
+		ev(model.ChangePositionReclassified, old.Position, cur.Position,
+			fmt.Sprintf("%s reclassified %s → %s", cur.WebName, old.Position, cur.Position))
+	}
+	return out
+}
+
+func fixtureChanges(old, cur model.Fixture, teams map[uint]string, now time.Time) []model.ChangeEvent {
+	var out []model.ChangeEvent
+	match := fmt.Sprintf("%s v %s", teamName(teams, cur.TeamHID), teamName(teams, cur.TeamAID))
+	ev := func(kind, oldV, newV, summary string) {
+		out = append(out, model.ChangeEvent{
+			Kind:       kind,
+			EntityType: "fixture",
+			EntityID:   uint(cur.ID),
+			OldValue:   oldV,
+			NewValue:   newV,
+			Summary:    summary,
+			CreatedAt:  now,
+		})
+	}
+
+	if !sameIntPtr(old.Event, cur.Event) || !sameTimePtr(old.KickoffTime, cur.KickoffTime) {
+		from, to := slot(old.Event, old.KickoffTime), slot(cur.Event, cur.KickoffTime)
+		ev(model.ChangeFixtureRescheduled, from, to,
+			fmt.Sprintf("%s rescheduled from %s to %s", match, from, to))
+	}
+	if !old.Finished && cur.Finished && cur.TeamHScore != nil && cur.TeamAScore != nil {
+		score := fmt.Sprintf("%d-%d", *cur.TeamHScore, *cur.TeamAScore)
+		ev(model.ChangeScoreFinalized, "", score, fmt.Sprintf("%s finished %s", match, score))
+	}
+	return out
+}
+
+func price(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// slot renders a fixture's gameweek and kickoff, e.g. "GW9 2025-10-25 14:00".
+func slot(event *int, kickoff *time.Time) string {
+	gw, ko := "GW?", "TBD"
+	if event != nil {
+		gw = fmt.Sprintf("GW%d", *event)
+	}
+	if kickoff != nil {
+		ko = kickoff.UTC().Format("2006-01-02 15:04")
+	}
+	return gw + " " + ko
+}
+
+func sameIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
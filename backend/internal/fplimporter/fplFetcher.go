@@ -0,0 +1,196 @@
+package fplimporter
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+const (
+	endpointBootstrap = "bootstrap-static"
+	endpointFixtures  = "fixtures"
+)
+
+type fetchResult struct {
+	Body        []byte
+	NotModified bool
+	state       model.FetchState
+}
+
+// fetchConditional GETs url with the validators stored for endpoint. On 304 it
+// returns NotModified and no body. On 200 the raw payload is written to disk;
+// the new validators are only persisted by commitFetch once the import succeeded,
+// so a failed import is retried in full next time.
+func (im *Importer) fetchConditional(ctx context.Context, endpoint, url string) (*fetchResult, error) {
+	state, err := im.store.FetchStates.Get(ctx, endpoint)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("load fetch state: %w", err)
+	}
+	state.Endpoint = endpoint
+	state.URL = url
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	resp, err := im.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return &fetchResult{NotModified: true, state: state}, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("fetch %s: unexpected status %d", endpoint, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", endpoint, err)
+	}
+
+	state.ETag = resp.Header.Get("ETag")
+	state.LastModified = resp.Header.Get("Last-Modified")
+	state.FetchedAt = time.Now()
+
+	if path, err := im.writeSnapshot(endpoint, body, state.FetchedAt); err != nil {
+		log.Printf("⚠️ Could not write %s snapshot: %v", endpoint, err)
+	} else {
+		state.SnapshotPath = path
+	}
+
+	return &fetchResult{Body: body, state: state}, nil
+}
+
+func (im *Importer) commitFetch(ctx context.Context, res *fetchResult) error {
+	if res == nil || res.NotModified {
+		return nil
+	}
+	return im.store.FetchStates.Save(ctx, res.state)
+}
+
+// forgetFetch records a replay of endpoint from snapshot: the validators are
+// cleared so the next import can't get a 304 for the data that was replaced,
+// and FetchedAt moves so caches keyed on the data version are dropped.
+func (im *Importer) forgetFetch(ctx context.Context, endpoint, url, snapshot string) error {
+	return im.store.FetchStates.Save(ctx, model.FetchState{
+		Endpoint:     endpoint,
+		URL:          url,
+		FetchedAt:    time.Now(),
+		SnapshotPath: snapshot,
+	})
+}
+
+func (im *Importer) snapshotDir(endpoint string) (string, error) {
+	dir := im.cacheDir
+	if !filepath.IsAbs(dir) {
+		ex, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(filepath.Dir(ex), dir)
+	}
+	return filepath.Join(dir, endpoint), nil
+}
+
+// writeSnapshot stores body as <cache>/<endpoint>/<timestamp>.json.gz and
+// refreshes latest.json.gz next to it.
+func (im *Importer) writeSnapshot(endpoint string, body []byte, at time.Time) (string, error) {
+	dir, err := im.snapshotDir(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, at.UTC().Format("20060102T150405Z")+".json.gz")
+	if err := writeGzip(path, body); err != nil {
+		return "", err
+	}
+	if err := writeGzip(filepath.Join(dir, "latest.json.gz"), body); err != nil {
+		return "", err
+	}
+	pruneSnapshots(dir, im.keepSnapshots)
+	return path, nil
+}
+
+func writeGzip(path string, body []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func pruneSnapshots(dir string, keep int) {
+	if keep <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && e.Name() != "latest.json.gz" && strings.HasSuffix(e.Name(), ".json.gz") {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) <= keep {
+		return
+	}
+	sort.Strings(names) // timestamps sort lexically
+	for _, name := range names[:len(names)-keep] {
+		_ = os.Remove(filepath.Join(dir, name))
+	}
+}
+
+func readSnapshot(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -7,59 +7,212 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"sync/atomic"
 	"time"
 
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
 	"gorm.io/datatypes"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
-const fplFixturesURL = "https://fantasy.premierleague.com/api/fixtures/?future=1"
+// All fixtures, not just ?future=1: finished games must stay in the feed so
+// score_finalized changes can be detected.
+const fplFixturesURL = "https://fantasy.premierleague.com/api/fixtures/"
 const fplPlayersURL = "https://fantasy.premierleague.com/api/bootstrap-static/"
 
-func ImportFPLData(ctx context.Context) error {
-	resp, err := http.Get(fplPlayersURL)
+// ErrImportInProgress is returned when another import already holds the lock.
+var ErrImportInProgress = errors.New("an FPL import is already running")
+
+// Progress receives phase and count updates from a running import. A nil
+// Progress is fine; jobs.Job is the implementation used by the API.
+type Progress interface {
+	Phase(name string, total int)
+	Advance(n int)
+}
+
+type nopProgress struct{}
+
+func (nopProgress) Phase(string, int) {}
+func (nopProgress) Advance(int)       {}
+
+// Importer pulls FPL data into the store. Only one import runs at a time.
+type Importer struct {
+	store         *repository.Store
+	client        *http.Client
+	bootstrapURL  string
+	fixturesURL   string
+	cacheDir      string
+	keepSnapshots int
+	importing     atomic.Bool
+	afterImport   []func()
+}
+
+func New(store *repository.Store, cfg *config.ImporterConfig) *Importer {
+	im := &Importer{
+		store:        store,
+		client:       &http.Client{Timeout: 60 * time.Second},
+		bootstrapURL: fplPlayersURL,
+		fixturesURL:  fplFixturesURL,
+		cacheDir:     "data/fpl-cache",
+	}
+	if cfg != nil {
+		if cfg.CacheDir != "" {
+			im.cacheDir = cfg.CacheDir
+		}
+		im.keepSnapshots = cfg.KeepSnapshots // defaults to 20 in config
+	}
+	return im
+}
+
+// AfterImport registers fn to run after every successful import job, e.g. to
+// queue work that depends on fresh data. Call it before the first import.
+func (im *Importer) AfterImport(fn func()) {
+	im.afterImport = append(im.afterImport, fn)
+}
+
+// Importing reports whether an import or replay is currently running.
+func (im *Importer) Importing() bool {
+	return im.importing.Load()
+}
+
+func (im *Importer) ImportFPLData(ctx context.Context, p Progress) error {
+	if !im.importing.CompareAndSwap(false, true) {
+		return ErrImportInProgress
+	}
+	defer im.importing.Store(false)
+
+	if p == nil {
+		p = nopProgress{}
+	}
+
+	res, err := im.fetchConditional(ctx, endpointBootstrap, im.bootstrapURL)
 	if err != nil {
 		return fmt.Errorf("❌ failed to fetch FPL data: %w", err)
 	}
-	defer resp.Body.Close()
 
+	if res.NotModified {
+		log.Println("⏭️ bootstrap-static not modified, skipping teams/players/chips")
+		p.Phase("teams", 0)
+		p.Phase("players", 0)
+		p.Phase("chips", 0)
+	} else {
+		if err := im.applyBootstrap(ctx, res.Body, p, true); err != nil {
+			return err
+		}
+		if err := im.commitFetch(ctx, res); err != nil {
+			return fmt.Errorf("❌ save fetch state failed: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := im.ImportFixtures(ctx, p); err != nil {
+		return fmt.Errorf("❌ insert fixtures failed: %w", err)
+	}
+
+	return nil
+}
+
+// LatestSnapshots returns the newest stored payload per endpoint, "" for an
+// endpoint that has none yet.
+func (im *Importer) LatestSnapshots() (bootstrapPath, fixturesPath string, err error) {
+	paths := make([]string, 2)
+	for i, endpoint := range []string{endpointBootstrap, endpointFixtures} {
+		dir, err := im.snapshotDir(endpoint)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := os.Stat(filepath.Join(dir, "latest.json.gz")); err == nil {
+			paths[i] = filepath.Join(dir, "latest.json.gz")
+		}
+	}
+	return paths[0], paths[1], nil
+}
+
+// ReplaySnapshot re-applies previously stored gzip payloads (see writeSnapshot)
+// without touching the network. Either path may be empty to skip that endpoint.
+//
+// The replayed data is treated as stale: no change events are recorded (the
+// moves aren't news), and the stored validators are dropped so the next
+// import fetches in full instead of getting a 304 and keeping the old data.
+func (im *Importer) ReplaySnapshot(ctx context.Context, bootstrapPath, fixturesPath string) error {
+	if !im.importing.CompareAndSwap(false, true) {
+		return ErrImportInProgress
+	}
+	defer im.importing.Store(false)
+
+	if bootstrapPath != "" {
+		body, err := readSnapshot(bootstrapPath)
+		if err != nil {
+			return fmt.Errorf("❌ read bootstrap snapshot: %w", err)
+		}
+		if err := im.applyBootstrap(ctx, body, nopProgress{}, false); err != nil {
+			return err
+		}
+		if err := im.forgetFetch(ctx, endpointBootstrap, im.bootstrapURL, bootstrapPath); err != nil {
+			return fmt.Errorf("❌ save fetch state failed: %w", err)
+		}
+	}
+	if fixturesPath != "" {
+		body, err := readSnapshot(fixturesPath)
+		if err != nil {
+			return fmt.Errorf("❌ read fixtures snapshot: %w", err)
+		}
+		if err := im.applyFixtures(ctx, body, nopProgress{}, false); err != nil {
+			return fmt.Errorf("❌ insert fixtures failed: %w", err)
+		}
+		if err := im.forgetFetch(ctx, endpointFixtures, im.fixturesURL, fixturesPath); err != nil {
+			return fmt.Errorf("❌ save fetch state failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyBootstrap stores a bootstrap-static payload. record is false for
+// replays, which leave the change feed alone.
+func (im *Importer) applyBootstrap(ctx context.Context, body []byte, p Progress, record bool) error {
 	var data model.FplResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := json.Unmarshal(body, &data); err != nil {
 		return fmt.Errorf("❌ failed to decode response: %w", err)
 	}
 
-	db := repository.DB
-
+	teams := make([]model.Team, 0, len(data.Teams))
 	for _, t := range data.Teams {
-		team := model.Team{
+		teams = append(teams, model.Team{
 			ID:        uint(t.ID),
 			Name:      t.Name,
 			ShortName: t.ShortName,
 			Code:      fmt.Sprintf("%d", t.Code),
-		}
-		if err := db.Save(&team).Error; err != nil {
-			return fmt.Errorf("❌ insert team failed: %w", err)
-		}
+		})
+	}
+	p.Phase("teams", len(teams))
+	if err := im.store.Teams.Upsert(ctx, teams); err != nil {
+		return fmt.Errorf("❌ insert team failed: %w", err)
 	}
+	p.Advance(len(teams))
 	log.Println("✅ Teams imported")
 
-	err = importPlayers(db, data.Players)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.Phase("players", len(data.Players))
+	stats, err := im.importPlayers(ctx, data.Players, p, record)
 	if err != nil {
 		return fmt.Errorf("❌ insert players failed: %w", err)
 	}
+	log.Printf("✅ Players imported: %d inserted, %d updated, %d unchanged, %d changes",
+		stats.Inserted, stats.Updated, stats.Unchanged, stats.Changes)
 
-	log.Println("✅ Players imported")
-
-	if err := ImportFixtures(ctx, db); err != nil {
-		return fmt.Errorf("❌ insert fixtures failed: %w", err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	log.Println("✅ Fixtures imported")
-
-	if err := importChips(db, data.Chips); err != nil {
+	p.Phase("chips", len(data.Chips))
+	if err := im.importChips(ctx, data.Chips, p); err != nil {
 		return fmt.Errorf("❌ insert chips failed: %w", err)
 	}
 	log.Println("✅ Chips imported")
@@ -67,83 +220,148 @@ func ImportFPLData(ctx context.Context) error {
 	return nil
 }
 
-func importPlayers(db *gorm.DB, players []model.FplPlayer) error {
-	for _, p := range players {
-		currentPrice := p.NowCost / 10.0
-		selectedPercent, _ := strconv.ParseFloat(p.SelectedByPercent, 64)
-		valueForm, _ := strconv.ParseFloat(p.ValueForm, 64)
-
-		player := model.Player{
-			ID:                uint(p.ID),
-			FirstName:         p.FirstName,
-			LastName:          p.SecondName,
-			TeamID:            uint(p.Team),
-			Position:          mapPosition(p.ElementType),
-			StartPrice:        currentPrice,
-			CurrentPrice:      currentPrice,
-			TotalPoints:       p.TotalPoints,
-			Form:              p.Form,
-			SelectedByPercent: selectedPercent,
-			TransfersIn:       p.TransfersIn,
-			TransfersInEvent:  p.TransfersInEvent,
-			TransfersOut:      p.TransfersOut,
-			TransfersOutEvent: p.TransfersOutEvent,
-			WebName:           p.WebName,
-			ValueForm:         valueForm,
-			EventPoints:       p.EventPoints,
-			UpdatedAt:         time.Now(),
-			CreatedAt:         time.Now(),
-			IctIndex:          p.IctIndex,
-		}
-
-		var existing model.Player
-		err := db.First(&existing, player.ID).Error
-
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("❌ failed to lookup player: %w", err)
-		}
-
-		if err == nil {
+// PlayerImportStats summarises one importPlayers run.
+type PlayerImportStats struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Changes   int // change events recorded
+}
+
+const playerBatchSize = 500
+
+func (im *Importer) importPlayers(ctx context.Context, players []model.FplPlayer, p Progress, record bool) (PlayerImportStats, error) {
+	var stats PlayerImportStats
+
+	existingRows, err := im.store.Players.List(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("❌ failed to load players: %w", err)
+	}
+	existing := make(map[uint]model.Player, len(existingRows))
+	for _, e := range existingRows {
+		existing[e.ID] = e
+	}
+	teams, err := im.loadTeamNames(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("❌ failed to load teams: %w", err)
+	}
+	// Don't flood the change feed with "added" for the very first import.
+	seeding := len(existingRows) == 0
+
+	now := time.Now()
+	changed := make([]model.Player, 0, len(players))
+	var events []model.ChangeEvent
+	for _, fp := range players {
+		player := toPlayer(fp, now)
+
+		if old, ok := existing[player.ID]; ok {
 			// Keep original start price
-			player.StartPrice = existing.StartPrice
-
-			// Check if any tracked fields changed
-			if existing.TeamID == player.TeamID &&
-				existing.Position == player.Position &&
-				existing.CurrentPrice == player.CurrentPrice &&
-				existing.TotalPoints == player.TotalPoints &&
-				existing.Form == player.Form &&
-				existing.SelectedByPercent == player.SelectedByPercent &&
-				existing.TransfersIn == player.TransfersIn &&
-				existing.TransfersInEvent == player.TransfersInEvent &&
-				existing.TransfersOut == player.TransfersOut &&
-				existing.TransfersOutEvent == player.TransfersOutEvent &&
-				existing.ValueForm == player.ValueForm &&
-				existing.EventPoints == player.EventPoints &&
-				existing.IctIndex == player.IctIndex {
+			player.StartPrice = old.StartPrice
+			player.CreatedAt = old.CreatedAt
+
+			if samePlayer(old, player) {
+				stats.Unchanged++
 				continue // ✅ Skip if unchanged
 			}
+			stats.Updated++
+			events = append(events, playerChanges(old, player, teams, now)...)
+		} else {
+			stats.Inserted++
+			if !seeding {
+				events = append(events, newPlayerEvent(player, teams, now))
+			}
+		}
+		changed = append(changed, player)
+	}
+
+	p.Advance(stats.Unchanged)
+	if !record {
+		events = nil
+	}
 
-			if err := db.Save(&player).Error; err != nil {
-				return fmt.Errorf("❌ failed to update player: %w", err)
+	// Rows and their change events go in together: events lost to a
+	// cancelled or failed run would never be re-emitted, since the next
+	// diff sees nothing changed.
+	err = im.store.WithTx(ctx, func(tx *repository.Store) error {
+		for start := 0; start < len(changed); start += playerBatchSize {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			log.Printf("🔁 Updated: %s %s", player.FirstName, player.LastName)
-		} else {
-			if err := db.Create(&player).Error; err != nil {
-				return fmt.Errorf("❌ failed to insert player: %w", err)
+			batch := changed[start:min(start+playerBatchSize, len(changed))]
+			if err := tx.Players.Upsert(ctx, batch); err != nil {
+				return fmt.Errorf("❌ failed to upsert players: %w", err)
 			}
-			log.Printf("➕ Inserted: %s %s", player.FirstName, player.LastName)
+			p.Advance(len(batch))
 		}
+		if err := tx.Changes.Append(ctx, events); err != nil {
+			return fmt.Errorf("❌ failed to record player changes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
 	}
-	return nil
+	stats.Changes = len(events)
+	return stats, nil
+}
+
+func toPlayer(p model.FplPlayer, now time.Time) model.Player {
+	currentPrice := p.NowCost / 10.0
+	selectedPercent, _ := strconv.ParseFloat(p.SelectedByPercent, 64)
+	valueForm, _ := strconv.ParseFloat(p.ValueForm, 64)
+
+	return model.Player{
+		ID:                uint(p.ID),
+		FirstName:         p.FirstName,
+		LastName:          p.SecondName,
+		TeamID:            uint(p.Team),
+		Position:          mapPosition(p.ElementType),
+		StartPrice:        currentPrice,
+		CurrentPrice:      currentPrice,
+		TotalPoints:       p.TotalPoints,
+		Form:              p.Form,
+		SelectedByPercent: selectedPercent,
+		TransfersIn:       p.TransfersIn,
+		TransfersInEvent:  p.TransfersInEvent,
+		TransfersOut:      p.TransfersOut,
+		TransfersOutEvent: p.TransfersOutEvent,
+		WebName:           p.WebName,
+		ValueForm:         valueForm,
+		EventPoints:       p.EventPoints,
+		UpdatedAt:         now,
+		CreatedAt:         now,
+		IctIndex:          p.IctIndex,
+	}
+}
+
+// samePlayer reports whether none of the tracked fields changed.
+func samePlayer(a, b model.Player) bool {
+	return a.FirstName == b.FirstName &&
+		a.LastName == b.LastName &&
+		a.WebName == b.WebName &&
+		a.TeamID == b.TeamID &&
+		a.Position == b.Position &&
+		a.CurrentPrice == b.CurrentPrice &&
+		a.TotalPoints == b.TotalPoints &&
+		a.Form == b.Form &&
+		a.SelectedByPercent == b.SelectedByPercent &&
+		a.TransfersIn == b.TransfersIn &&
+		a.TransfersInEvent == b.TransfersInEvent &&
+		a.TransfersOut == b.TransfersOut &&
+		a.TransfersOutEvent == b.TransfersOutEvent &&
+		a.ValueForm == b.ValueForm &&
+		a.EventPoints == b.EventPoints &&
+		a.IctIndex == b.IctIndex
 }
-func importChips(db *gorm.DB, chips []model.FplChip) error {
+
+func (im *Importer) importChips(ctx context.Context, chips []model.FplChip, p Progress) error {
+	rows := make([]model.Chip, 0, len(chips))
 	for _, c := range chips {
 		ovBytes, err := json.Marshal(c.Overrides)
 		if err != nil {
 			return fmt.Errorf("marshal overrides: %w", err)
 		}
-		row := model.Chip{
+		rows = append(rows, model.Chip{
 			ID:         uint(c.ID),
 			Name:       c.Name,
 			Number:     c.Number,
@@ -152,24 +370,43 @@ func importChips(db *gorm.DB, chips []model.FplChip) error {
 			ChipType:   c.ChipType,
 			CreatedAt:  time.Now(),
 			Overrides:  datatypes.JSON(ovBytes),
-		}
-		if err := db.Save(&row).Error; err != nil {
-			return err
-		}
+		})
 	}
+	if err := im.store.Chips.Upsert(ctx, rows); err != nil {
+		return err
+	}
+	p.Advance(len(rows))
 	return nil
 }
 
-func ImportFixtures(ctx context.Context, db *gorm.DB) error {
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fplFixturesURL, nil)
-	resp, err := http.DefaultClient.Do(req)
+func (im *Importer) ImportFixtures(ctx context.Context, p Progress) error {
+	if p == nil {
+		p = nopProgress{}
+	}
+
+	res, err := im.fetchConditional(ctx, endpointFixtures, im.fixturesURL)
 	if err != nil {
-		return fmt.Errorf("fetch fixtures: %w", err)
+		return err
+	}
+	if res.NotModified {
+		log.Println("⏭️ fixtures not modified, skipping")
+		p.Phase("fixtures", 0)
+		return nil
 	}
-	defer resp.Body.Close()
 
+	if err := im.applyFixtures(ctx, res.Body, p, true); err != nil {
+		return err
+	}
+	if err := im.commitFetch(ctx, res); err != nil {
+		return fmt.Errorf("save fetch state: %w", err)
+	}
+	log.Println("✅ Fixtures imported")
+	return nil
+}
+
+func (im *Importer) applyFixtures(ctx context.Context, body []byte, p Progress, record bool) error {
 	var rowsDTO []model.FplFixtureDTO
-	if err := json.NewDecoder(resp.Body).Decode(&rowsDTO); err != nil {
+	if err := json.Unmarshal(body, &rowsDTO); err != nil {
 		return fmt.Errorf("decode fixtures: %w", err)
 	}
 
@@ -194,16 +431,59 @@ func ImportFixtures(ctx context.Context, db *gorm.DB) error {
 		})
 	}
 
-	// Upsert on fixture ID so re-runs are safe
-	return db.Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{
-			"event", "kickoff_time", "started", "finished", "provisional_start_time",
-			"team_h_id", "team_a_id", "team_h_score", "team_a_score",
-			"team_h_difficulty", "team_a_difficulty", "minutes", "pulse_id", "code",
-		}),
-	}).Create(&rows).Error
+	p.Phase("fixtures", len(rows))
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var events []model.ChangeEvent
+	if record {
+		var err error
+		if events, err = im.fixtureEvents(ctx, rows); err != nil {
+			return err
+		}
+	}
+	err := im.store.WithTx(ctx, func(tx *repository.Store) error {
+		if err := tx.Fixtures.Upsert(ctx, rows); err != nil {
+			return err
+		}
+		if err := tx.Changes.Append(ctx, events); err != nil {
+			return fmt.Errorf("record fixture changes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.Advance(len(rows))
+	return nil
+}
+
+// fixtureEvents diffs incoming fixtures against what is stored.
+func (im *Importer) fixtureEvents(ctx context.Context, rows []model.Fixture) ([]model.ChangeEvent, error) {
+	existingRows, err := im.store.Fixtures.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load fixtures: %w", err)
+	}
+	existing := make(map[int]model.Fixture, len(existingRows))
+	for _, f := range existingRows {
+		existing[f.ID] = f
+	}
+	teams, err := im.loadTeamNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load teams: %w", err)
+	}
+
+	now := time.Now()
+	var events []model.ChangeEvent
+	for _, f := range rows {
+		if old, ok := existing[f.ID]; ok {
+			events = append(events, fixtureChanges(old, f, teams, now)...)
+		}
+	}
+	return events, nil
 }
+
 func mapPosition(elementType int) string {
 	switch elementType {
 	case 1:
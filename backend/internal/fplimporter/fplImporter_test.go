@@ -0,0 +1,98 @@
+package fplimporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository/memory"
+)
+
+func bootstrapJSON(nowCost int) string {
+	return fmt.Sprintf(`{"teams": [{"id": 1, "name": "Arsenal", "short_name": "ARS", "code": 3}],
+		"elements": [{"id": 7, "first_name": "Bukayo", "second_name": "Saka", "web_name": "Saka", "team": 1,
+			"element_type": 3, "now_cost": %d, "form": "7.0", "selected_by_percent": "40.1"}],
+		"chips": []}`, nowCost)
+}
+
+// fakeFPL serves the current payloads and answers 304 when the client
+// already has them, like the real API.
+func fakeFPL(t *testing.T, bootstrap string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, etag := bootstrap, `"b-current"`
+		if r.URL.Path == "/fixtures/" {
+			body, etag = `[]`, `"f-current"`
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReplayThenImportRefreshes(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	srv := fakeFPL(t, bootstrapJSON(100))
+	im := New(store, &config.ImporterConfig{CacheDir: t.TempDir(), KeepSnapshots: 5})
+	im.bootstrapURL, im.fixturesURL = srv.URL+"/bootstrap-static/", srv.URL+"/fixtures/"
+
+	price := func() float64 {
+		p, err := store.Players.Get(ctx, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.CurrentPrice
+	}
+	changes := func() int {
+		events, err := store.Changes.List(ctx, repository.ChangeFilter{Limit: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(events)
+	}
+
+	if err := im.ImportFPLData(ctx, nil); err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if price() != 10.0 {
+		t.Fatalf("price after import %.1f, want 10.0", price())
+	}
+	imported, _ := store.FetchStates.LastFetchedAt(ctx)
+
+	// an older snapshot where Saka was cheaper
+	old := filepath.Join(t.TempDir(), "old.json.gz")
+	if err := writeGzip(old, []byte(bootstrapJSON(95))); err != nil {
+		t.Fatal(err)
+	}
+	if err := im.ReplaySnapshot(ctx, old, ""); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if price() != 9.5 {
+		t.Fatalf("price after replay %.1f, want 9.5", price())
+	}
+	if n := changes(); n != 0 {
+		t.Errorf("replay recorded %d change events, want none", n)
+	}
+	if replayed, _ := store.FetchStates.LastFetchedAt(ctx); !replayed.After(imported) {
+		t.Errorf("data version didn't move on replay: %s, was %s", replayed, imported)
+	}
+
+	// upstream hasn't changed, but the DB has, so this must not be a 304
+	if err := im.ImportFPLData(ctx, nil); err != nil {
+		t.Fatalf("import after replay: %v", err)
+	}
+	if price() != 10.0 {
+		t.Errorf("price after re-import %.1f, want 10.0 again", price())
+	}
+}
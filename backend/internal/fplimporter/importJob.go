@@ -0,0 +1,25 @@
+package fplimporter
+
+import (
+	"context"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+)
+
+// JobKind identifies FPL import jobs in the job manager.
+const JobKind = "import-fpl"
+
+// SubmitImport queues ImportFPLData on m. trigger is recorded on the job
+// ("api", "schedule", ...) so the status endpoint can tell runs apart.
+// AfterImport hooks run once the import succeeds.
+func (im *Importer) SubmitImport(m *jobs.Manager, trigger string) (*jobs.Job, error) {
+	return m.Submit(JobKind, trigger, func(ctx context.Context, job *jobs.Job) error {
+		if err := im.ImportFPLData(ctx, job); err != nil {
+			return err
+		}
+		for _, fn := range im.afterImport {
+			fn()
+		}
+		return nil
+	})
+}
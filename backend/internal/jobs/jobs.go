@@ -0,0 +1,276 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// How many finished jobs are kept around for GET /admin/jobs/{id}.
+const keepFinished = 50
+
+var (
+	// ErrAlreadyRunning is returned by Submit when a job of the same kind is active.
+	ErrAlreadyRunning = errors.New("a job of this kind is already running")
+	ErrNotFound       = errors.New("job not found")
+	ErrFinished       = errors.New("job already finished")
+)
+
+type PhaseProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Snapshot is a copy of a job's state that is safe to serialise.
+type Snapshot struct {
+	ID         string                   `json:"id"`
+	Kind       string                   `json:"kind"`
+	Trigger    string                   `json:"trigger"`
+	State      State                    `json:"state"`
+	Phase      string                   `json:"phase,omitempty"`
+	Phases     []string                 `json:"phases,omitempty"`
+	Progress   map[string]PhaseProgress `json:"progress"`
+	Errors     []string                 `json:"errors,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	StartedAt  *time.Time               `json:"started_at,omitempty"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+}
+
+// Job is a unit of background work. It doubles as a progress sink: the
+// running function reports phases and counts through Phase and Advance.
+type Job struct {
+	mu     sync.Mutex
+	snap   Snapshot
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func (j *Job) Phase(name string, total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snap.Phase = name
+	if _, seen := j.snap.Progress[name]; !seen {
+		j.snap.Phases = append(j.snap.Phases, name)
+	}
+	j.snap.Progress[name] = PhaseProgress{Total: total}
+}
+
+func (j *Job) Advance(n int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	p := j.snap.Progress[j.snap.Phase]
+	p.Done += n
+	j.snap.Progress[j.snap.Phase] = p
+}
+
+// Warn records a non-fatal problem without failing the job.
+func (j *Job) Warn(msg string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.snap.Errors = append(j.snap.Errors, msg)
+}
+
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := j.snap
+	s.Progress = make(map[string]PhaseProgress, len(j.snap.Progress))
+	for k, v := range j.snap.Progress {
+		s.Progress[k] = v
+	}
+	s.Phases = append([]string(nil), j.snap.Phases...)
+	s.Errors = append([]string(nil), j.snap.Errors...)
+	return s
+}
+
+// Done is closed once the job has finished, whatever the outcome.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Err is the error the job finished with; only valid after Done is closed.
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *Job) active() bool {
+	return j.snap.State == StateQueued || j.snap.State == StateRunning
+}
+
+type Func func(ctx context.Context, job *Job) error
+
+type Manager struct {
+	base    context.Context
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running sync.WaitGroup
+}
+
+// NewManager creates a manager whose jobs are cancelled when ctx is.
+func NewManager(ctx context.Context) *Manager {
+	return &Manager{base: ctx, jobs: map[string]*Job{}}
+}
+
+// Submit starts fn in the background. If a job of the same kind is still
+// active it returns that job together with ErrAlreadyRunning.
+func (m *Manager) Submit(kind, trigger string, fn Func) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		busy := j.snap.Kind == kind && j.active()
+		j.mu.Unlock()
+		if busy {
+			return j, ErrAlreadyRunning
+		}
+	}
+
+	ctx, cancel := context.WithCancel(m.base)
+	job := &Job{
+		snap: Snapshot{
+			ID:        uuid.NewString(),
+			Kind:      kind,
+			Trigger:   trigger,
+			State:     StateQueued,
+			Progress:  map[string]PhaseProgress{},
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.jobs[job.snap.ID] = job
+	m.prune()
+
+	m.running.Add(1)
+	go m.run(ctx, job, fn)
+	return job, nil
+}
+
+// Wait blocks until every submitted job has finished or ctx is done. Use it
+// after cancelling the manager's context to let jobs unwind on shutdown.
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) run(ctx context.Context, job *Job, fn Func) {
+	defer m.running.Done()
+	defer close(job.done)
+	defer job.cancel()
+
+	now := time.Now()
+	job.mu.Lock()
+	job.snap.State = StateRunning
+	job.snap.StartedAt = &now
+	id, kind := job.snap.ID, job.snap.Kind
+	job.mu.Unlock()
+
+	err := fn(ctx, job)
+
+	finished := time.Now()
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.err = err
+	job.snap.FinishedAt = &finished
+	switch {
+	case err == nil:
+		job.snap.State = StateSucceeded
+		job.snap.Phase = ""
+	case errors.Is(err, context.Canceled):
+		job.snap.State = StateCancelled
+		job.snap.Errors = append(job.snap.Errors, "cancelled")
+	default:
+		job.snap.State = StateFailed
+		job.snap.Errors = append(job.snap.Errors, err.Error())
+	}
+	log.Printf("🏁 Job %s (%s) %s in %s", id, kind, job.snap.State, finished.Sub(now).Round(time.Millisecond))
+}
+
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	return j, ok
+}
+
+// List returns snapshots of all known jobs, newest first.
+func (m *Manager) List() []Snapshot {
+	m.mu.Lock()
+	all := make([]*Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		all = append(all, j)
+	}
+	m.mu.Unlock()
+
+	out := make([]Snapshot, 0, len(all))
+	for _, j := range all {
+		out = append(out, j.Snapshot())
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].CreatedAt.After(out[b].CreatedAt) })
+	return out
+}
+
+// Cancel asks an active job to stop; the job itself decides when it has.
+func (m *Manager) Cancel(id string) error {
+	j, ok := m.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	j.mu.Lock()
+	active := j.active()
+	j.mu.Unlock()
+	if !active {
+		return ErrFinished
+	}
+	j.cancel()
+	return nil
+}
+
+// prune drops the oldest finished jobs beyond keepFinished; m.mu must be held.
+func (m *Manager) prune() {
+	var finished []*Job
+	for _, j := range m.jobs {
+		j.mu.Lock()
+		if !j.active() {
+			finished = append(finished, j)
+		}
+		j.mu.Unlock()
+	}
+	if len(finished) <= keepFinished {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool {
+		return finished[a].snap.CreatedAt.Before(finished[b].snap.CreatedAt)
+	})
+	for _, j := range finished[:len(finished)-keepFinished] {
+		delete(m.jobs, j.snap.ID)
+	}
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// AIUsage is one answered AI request with the tokens it cost. Signed-in
+// callers are recorded by UserID, anonymous ones by Client (their IP).
+// Cache hits are recorded too, with Cached set and no tokens.
+type AIUsage struct {
+	ID               uint   `gorm:"primaryKey"`
+	UserID           *uint  `gorm:"index"`
+	Client           string `gorm:"size:64;index"`
+	Endpoint         string `gorm:"size:32;not null"` // e.g. "ask-ai", "conversation"
+	Provider         string `gorm:"size:32"`
+	Model            string `gorm:"size:100"`
+	PromptVersion    string `gorm:"size:100"` // e.g. "persona@1 squad@2"
+	PromptTokens     int
+	CompletionTokens int
+	Cached           bool
+	CreatedAt        time.Time `gorm:"index"`
+}
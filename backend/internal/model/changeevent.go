@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Change kinds emitted by the importer.
+const (
+	ChangePriceRise            = "price_rise"
+	ChangePriceFall            = "price_fall"
+	ChangeTeamTransfer         = "team_transfer"
+	ChangePlayerAdded          = "player_added"
+	ChangePositionReclassified = "position_reclassified"
+	ChangeFixtureRescheduled   = "fixture_rescheduled"
+	ChangeScoreFinalized       = "score_finalized"
+)
+
+// ChangeEvent is one structured entry of the import change feed.
+type ChangeEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Kind       string    `gorm:"size:32;index;not null" json:"kind"`
+	EntityType string    `gorm:"size:16;not null" json:"entity_type"` // "player" or "fixture"
+	EntityID   uint      `gorm:"index;not null" json:"entity_id"`
+	OldValue   string    `gorm:"size:64" json:"old_value,omitempty"`
+	NewValue   string    `gorm:"size:64" json:"new_value,omitempty"`
+	Summary    string    `gorm:"not null" json:"summary"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
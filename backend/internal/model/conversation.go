@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Conversation is a chat thread with the assistant. Once the history no
+// longer fits the prompt budget, older turns are folded into Summary and
+// SummarizedThrough moves forward.
+type Conversation struct {
+	ID                uint   `gorm:"primaryKey"`
+	UserID            uint   `gorm:"index;not null"`
+	Title             string `gorm:"size:200"`
+	Summary           string `gorm:"type:text"`
+	SummarizedThrough uint   // last ConversationMessage.ID covered by Summary
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// ConversationMessage is one turn. Role is "user" or "assistant".
+type ConversationMessage struct {
+	ID             uint   `gorm:"primaryKey"`
+	ConversationID uint   `gorm:"index;not null"`
+	Role           string `gorm:"size:16;not null"`
+	Content        string `gorm:"type:text;not null"`
+	Model          string `gorm:"size:100"` // set on assistant turns
+	CreatedAt      time.Time
+}
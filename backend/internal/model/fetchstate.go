@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// FetchState remembers the validators of the last successfully imported
+// payload per FPL endpoint so the next import can send a conditional request.
+type FetchState struct {
+	Endpoint     string `gorm:"primaryKey;size:64"`
+	URL          string `gorm:"not null"`
+	ETag         string `gorm:"size:255"`
+	LastModified string `gorm:"size:64"`
+	FetchedAt    time.Time
+	SnapshotPath string
+}
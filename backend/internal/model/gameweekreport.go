@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// GameweekReport is the AI-written preview of one gameweek, generated once
+// its fixtures are known. Tokens it cost are in AIUsage.
+type GameweekReport struct {
+	ID            uint      `gorm:"primaryKey"`
+	Gameweek      int       `gorm:"uniqueIndex;not null"`
+	Markdown      string    `gorm:"type:text;not null"`
+	Provider      string    `gorm:"size:32"`
+	Model         string    `gorm:"size:100"`
+	PromptVersion string    `gorm:"size:100"`
+	DataAsOf      time.Time // last FPL import the report was written from
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
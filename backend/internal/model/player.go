@@ -24,7 +24,6 @@ type Player struct {
 	TotalPoints       int       `gorm:"not null"`
 	CreatedAt         time.Time `gorm:"CreatedAt" json:"-"`
 	UpdatedAt         time.Time `gorm:"UpdatedAt" json:"-"`
-	PrettyUpdatedAt   string    `gorm:"-" json:"UpdatedAt"`
 	IctIndex          string    `gorm:"IctIndex" json:"ict_index"`
 }
 
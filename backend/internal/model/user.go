@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// User is an account. Email is stored lowercased and is unique.
+type User struct {
+	ID           uint   `gorm:"primaryKey"`
+	Email        string `gorm:"size:254;uniqueIndex;not null"`
+	DisplayName  string `gorm:"size:100;not null"`
+	PasswordHash string `gorm:"size:255;not null"` // bcrypt
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Session is a login. Only the SHA-256 of the cookie token is stored, so a
+// leaked table can't be replayed.
+type Session struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	UserAgent string    `gorm:"size:255"`
+	IP        string    `gorm:"size:64"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
@@ -2,6 +2,7 @@ package model
 
 type UserTeam struct {
 	ID       uint   `gorm:"primaryKey"`
-	UserName string `gorm:"not null"`
+	UserID   *uint  `gorm:"index"`    // owner; nil for squads created before accounts
+	UserName string `gorm:"not null"` // squad name shown in the API (column predates accounts)
 	Points   int
 }
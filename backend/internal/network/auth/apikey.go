@@ -0,0 +1,148 @@
+// Package auth guards v1 routes. Admin routes take static API keys from
+// [auth] in the config; every admin call is written to the audit log.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"github.com/go-chi/chi/middleware"
+)
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // read admin state (jobs, sync status)
+	RoleAdmin  Role = "admin"  // everything, including imports and cancels
+
+	// RoleUser marks routes for signed-in accounts (session cookie) rather
+	// than API keys; see Sessions.RequireUser.
+	RoleUser Role = "user"
+	// RoleGuest marks public routes that still want to know who is signed
+	// in, if anyone; see Sessions.OptionalUser.
+	RoleGuest Role = "guest"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Principal is whoever a request authenticated as.
+type Principal struct {
+	Name  string
+	Roles []Role
+}
+
+// Has reports whether p may act as role; admin implies viewer.
+func (p *Principal) Has(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type apiKey struct {
+	principal Principal
+	hash      [sha256.Size]byte
+}
+
+// KeyStore checks API keys. Only hashes are kept in memory.
+type KeyStore struct {
+	keys []apiKey
+}
+
+func NewKeyStore(cfg []config.APIKeyConfig) *KeyStore {
+	ks := &KeyStore{}
+	for _, k := range cfg {
+		p := Principal{Name: k.Name}
+		for _, r := range k.Roles {
+			p.Roles = append(p.Roles, Role(r))
+		}
+		ks.keys = append(ks.keys, apiKey{principal: p, hash: sha256.Sum256([]byte(k.Key))})
+	}
+	if len(ks.keys) == 0 {
+		log.Println("⚠️ No admin API keys configured; /v1/admin is locked (set ADMIN_API_KEY or [[auth.api_keys]])")
+	}
+	return ks
+}
+
+// lookup compares against every key in constant time, so neither the match
+// position nor a partial match shows up in timing.
+func (ks *KeyStore) lookup(key string) (*Principal, bool) {
+	if ks == nil || key == "" {
+		return nil, false
+	}
+	sum := sha256.Sum256([]byte(key))
+	var found *Principal
+	for i := range ks.keys {
+		if subtle.ConstantTimeCompare(sum[:], ks.keys[i].hash[:]) == 1 {
+			found = &ks.keys[i].principal
+		}
+	}
+	return found, found != nil
+}
+
+func keyFromRequest(r *http.Request) string {
+	if k := r.Header.Get(APIKeyHeader); k != "" {
+		return k
+	}
+	if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+type ctxKey struct{}
+
+// PrincipalFrom returns the authenticated caller, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(*Principal)
+	return p, ok
+}
+
+// Require rejects requests without a key carrying role and audit-logs every
+// attempt, allowed or not.
+func (ks *KeyStore) Require(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			who := "-"
+			defer func() {
+				log.Printf("🔐 audit [%s] %s %s %s by=%s ip=%s -> %d (%s)",
+					response.RequestIDFrom(r.Context()), role, r.Method, r.URL.Path, who,
+					ClientIP(r), ww.Status(), time.Since(start).Round(time.Millisecond))
+			}()
+
+			p, ok := ks.lookup(keyFromRequest(r))
+			if !ok {
+				ww.Header().Set("WWW-Authenticate", `Bearer realm="fpl-assistant-admin"`)
+				response.Error(ww, r, http.StatusUnauthorized, response.CodeUnauthorized, "Missing or invalid API key")
+				return
+			}
+			who = p.Name
+			if !p.Has(role) {
+				response.Error(ww, r, http.StatusForbidden, response.CodeForbidden, "This API key lacks the "+string(role)+" role")
+				return
+			}
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), ctxKey{}, p)))
+		})
+	}
+}
+
+// ClientIP is the remote address without the port.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
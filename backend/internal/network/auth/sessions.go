@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const SessionCookie = "fpl_session"
+
+var (
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// Sessions handles user accounts and cookie logins. The cookie carries a
+// random token; the database only sees its SHA-256.
+type Sessions struct {
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	ttl      time.Duration
+	secure   bool
+}
+
+func NewSessions(store *repository.Store, cfg *config.AuthConfig, tls bool) *Sessions {
+	return &Sessions{
+		users:    store.Users,
+		sessions: store.Sessions,
+		ttl:      cfg.SessionTTL,
+		secure:   cfg.SecureCookies || tls,
+	}
+}
+
+// NormalizeEmail is how emails are compared and stored.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *Sessions) Signup(ctx context.Context, email, displayName, password string) (model.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, fmt.Errorf("❌ failed to hash password: %w", err)
+	}
+	user := model.User{
+		Email:        NormalizeEmail(email),
+		DisplayName:  strings.TrimSpace(displayName),
+		PasswordHash: string(hash),
+	}
+	if err := s.users.Create(ctx, &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.User{}, ErrEmailTaken
+		}
+		return model.User{}, fmt.Errorf("❌ failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// Used to burn the same bcrypt time for unknown emails, so response timing
+// doesn't reveal which addresses have accounts.
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+func (s *Sessions) Login(ctx context.Context, email, password string) (model.User, error) {
+	user, err := s.users.GetByEmail(ctx, NormalizeEmail(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return model.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.User{}, fmt.Errorf("❌ failed to load user: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return model.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Start creates a session for user and sets the cookie.
+func (s *Sessions) Start(w http.ResponseWriter, r *http.Request, user model.User) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("❌ failed to generate session token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	session := model.Session{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		UserAgent: truncate(r.UserAgent(), 255),
+		IP:        ClientIP(r),
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.sessions.Create(r.Context(), &session); err != nil {
+		return fmt.Errorf("❌ failed to save session: %w", err)
+	}
+	// Logins are rare enough to double as the cleanup trigger
+	_ = s.sessions.DeleteExpired(r.Context(), now)
+
+	http.SetCookie(w, s.cookie(token, session.ExpiresAt))
+	return nil
+}
+
+// End deletes the current session (if any) and clears the cookie.
+func (s *Sessions) End(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, s.cookie("", time.Unix(0, 0)))
+	c, err := r.Cookie(SessionCookie)
+	if err != nil || c.Value == "" {
+		return nil
+	}
+	return s.sessions.DeleteByTokenHash(r.Context(), hashToken(c.Value))
+}
+
+func (s *Sessions) cookie(value string, expires time.Time) *http.Cookie {
+	c := &http.Cookie{
+		Name:     SessionCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if value == "" {
+		c.MaxAge = -1
+	}
+	return c
+}
+
+type userKey struct{}
+
+// UserFrom returns the signed-in user set by RequireUser.
+func UserFrom(ctx context.Context) (model.User, bool) {
+	u, ok := ctx.Value(userKey{}).(model.User)
+	return u, ok
+}
+
+// RequireUser rejects requests without a valid session cookie.
+func (s *Sessions) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(SessionCookie)
+		if err != nil || c.Value == "" {
+			response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Sign in required")
+			return
+		}
+		user, err := s.lookup(r.Context(), c.Value)
+		if errors.Is(err, repository.ErrNotFound) {
+			http.SetCookie(w, s.cookie("", time.Unix(0, 0)))
+			response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Session expired, please sign in again")
+			return
+		}
+		if err != nil {
+			response.Internal(w, r, "Failed to check session", err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// OptionalUser is RequireUser for public routes: without a valid session
+// the request goes through anonymously.
+func (s *Sessions) OptionalUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(SessionCookie)
+		if err != nil || c.Value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := s.lookup(r.Context(), c.Value)
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Printf("⚠️ session lookup failed, treating request as anonymous: %v", err)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+func (s *Sessions) lookup(ctx context.Context, token string) (model.User, error) {
+	session, err := s.sessions.GetByTokenHash(ctx, hashToken(token), time.Now())
+	if err != nil {
+		return model.User{}, err
+	}
+	user, err := s.users.Get(ctx, session.UserID)
+	if err != nil {
+		return model.User{}, fmt.Errorf("❌ failed to load user: %w", err)
+	}
+	return user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
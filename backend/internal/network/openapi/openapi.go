@@ -0,0 +1,252 @@
+// Package openapi builds an OpenAPI 3 document from a route table and the Go
+// types handlers read and write, so the spec can't drift from the code.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation documents one route. Request and Response are zero values of the
+// body types (nil for none); only their types are used.
+type Operation struct {
+	Method          string
+	Path            string // chi-style, e.g. /admin/jobs/{id}
+	Summary         string
+	Description     string
+	Tag             string
+	Params          []Param
+	Request         any
+	RequestOptional bool // the Request body may be left out
+	Response        any
+	ResponseType    string   // media type of Response, default application/json
+	Status          int      // success status, default 200
+	Errors          []int    // documented error statuses, all using the error envelope
+	Security        []string // names from Builder.SecuritySchemes, any of which is accepted
+	AuthOptional    bool     // Security may also be left out entirely
+}
+
+type Param struct {
+	Name        string
+	In          string // "query", "path" or "header"
+	Description string
+	Required    bool
+	Type        string // "string" (default), "integer", "boolean"
+	Format      string
+}
+
+// Q is shorthand for an optional query parameter.
+func Q(name, typ, description string) Param {
+	return Param{Name: name, In: "query", Type: typ, Description: description}
+}
+
+// Info goes into the document header.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers,omitempty"`
+	Tags       []TagDoc                        `json:"tags,omitempty"`
+	Paths      map[string]map[string]*opObject `json:"paths"`
+	Components components                      `json:"components"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type TagDoc struct {
+	Name string `json:"name"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"` // "apiKey" or "http"
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type opObject struct {
+	Summary     string                 `json:"summary,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	OperationID string                 `json:"operationId"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []paramObject          `json:"parameters,omitempty"`
+	RequestBody *bodyObject            `json:"requestBody,omitempty"`
+	Responses   map[string]*respObject `json:"responses"`
+	Security    []map[string][]string  `json:"security,omitempty"`
+}
+
+type paramObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type bodyObject struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type respObject struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Builder collects operations; Build renders the document.
+type Builder struct {
+	Info            Info
+	BasePath        string // prefix for every path, e.g. /v1
+	ErrorModel      any    // body type of every documented error
+	SecuritySchemes map[string]SecurityScheme
+	ops             []Operation
+}
+
+func (b *Builder) Add(ops ...Operation) {
+	b.ops = append(b.ops, ops...)
+}
+
+func (b *Builder) Build() *Document {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    b.Info,
+		Servers: []Server{{URL: b.BasePath}},
+		Paths:   map[string]map[string]*opObject{},
+	}
+
+	var errSchema *Schema
+	if b.ErrorModel != nil {
+		errSchema = s.of(reflect.TypeOf(b.ErrorModel))
+	}
+
+	tags := map[string]bool{}
+	for _, op := range b.ops {
+		item := doc.Paths[op.Path]
+		if item == nil {
+			item = map[string]*opObject{}
+			doc.Paths[op.Path] = item
+		}
+		o := &opObject{
+			Summary:     op.Summary,
+			Description: op.Description,
+			OperationID: operationID(op),
+			Responses:   map[string]*respObject{},
+		}
+		if op.Tag != "" {
+			o.Tags = []string{op.Tag}
+			tags[op.Tag] = true
+		}
+
+		for _, name := range pathParams(op.Path) {
+			o.Parameters = append(o.Parameters, paramObject{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, p := range op.Params {
+			typ := p.Type
+			if typ == "" {
+				typ = "string"
+			}
+			o.Parameters = append(o.Parameters, paramObject{
+				Name: p.Name, In: p.In, Description: p.Description, Required: p.Required,
+				Schema: &Schema{Type: typ, Format: p.Format},
+			})
+		}
+
+		if op.Request != nil {
+			o.RequestBody = &bodyObject{Required: !op.RequestOptional, Content: map[string]mediaType{
+				"application/json": {Schema: s.of(reflect.TypeOf(op.Request))},
+			}}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := &respObject{Description: http.StatusText(status)}
+		if op.Response != nil {
+			ctype := op.ResponseType
+			if ctype == "" {
+				ctype = "application/json"
+			}
+			ok.Content = map[string]mediaType{ctype: {Schema: s.of(reflect.TypeOf(op.Response))}}
+		}
+		o.Responses[strconv.Itoa(status)] = ok
+		for _, code := range op.Errors {
+			r := &respObject{Description: http.StatusText(code)}
+			if errSchema != nil {
+				r.Content = map[string]mediaType{"application/json": {Schema: errSchema}}
+			}
+			o.Responses[strconv.Itoa(code)] = r
+		}
+		for _, name := range op.Security {
+			o.Security = append(o.Security, map[string][]string{name: {}})
+		}
+		if op.AuthOptional && len(o.Security) > 0 {
+			o.Security = append(o.Security, map[string][]string{}) // {} = anonymous
+		}
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, TagDoc{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	doc.Components = components{Schemas: s.defs, SecuritySchemes: b.SecuritySchemes}
+	return doc
+}
+
+// pathParams returns the {name} segments of a chi pattern.
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := strings.Trim(seg, "{}")
+			// chi allows {id:[0-9]+}
+			name, _, _ = strings.Cut(name, ":")
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// operationID derives a stable camelCase id, e.g. GET /admin/jobs/{id} ->
+// getAdminJobsById. Client generators use it for function names.
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, seg := range strings.Split(op.Path, "/") {
+		if seg == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, "{") {
+			name, _, _ := strings.Cut(strings.Trim(seg, "{}"), ":")
+			b.WriteString("By")
+			seg = name
+		}
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
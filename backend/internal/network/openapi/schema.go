@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema that OpenAPI 3.0 uses and we need.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas turns Go types into component schemas. Named structs become
+// $refs so each DTO shows up once under components.schemas.
+type schemas struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := s.of(t.Elem())
+		if inner.Ref != "" {
+			// OpenAPI 3.0 ignores siblings of $ref, so nullable refs stay plain
+			return inner
+		}
+		inner.Nullable = true
+		return inner
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.define(t)}
+	default:
+		// interface{} and friends: anything goes
+		return &Schema{}
+	}
+}
+
+func (s *schemas) define(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.defs[name]; taken {
+		// Same type name in two packages: qualify the newcomer
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	s.names[t] = name
+	s.defs[name] = &Schema{} // placeholder so recursive types terminate
+	s.defs[name] = s.object(t)
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, obj)
+	return obj
+}
+
+func (s *schemas) fields(t reflect.Type, obj *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name are flattened, like encoding/json does
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, obj)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := s.of(f.Type)
+		if doc := f.Tag.Get("doc"); doc != "" && prop.Ref == "" {
+			prop.Description = doc
+		}
+		if enum := f.Tag.Get("enum"); enum != "" && prop.Ref == "" {
+			for _, v := range strings.Split(enum, ",") {
+				prop.Enum = append(prop.Enum, v)
+			}
+		}
+		obj.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package response
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type ctxKey struct{}
+
+// Accept caller-supplied IDs only when they are short and log-safe
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID tags each request with an ID (reusing a sane incoming
+// X-Request-ID), echoes it in the response header and stores it in the
+// context for logs and error bodies.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, id)))
+	})
+}
+
+// RequestIDFrom returns the request ID, or "" outside RequestID.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Recover turns a handler panic into a 500 envelope instead of a dropped
+// connection.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			log.Printf("💥 [%s] panic in %s %s: %v\n%s", RequestIDFrom(r.Context()), r.Method, r.URL.Path, rec, debug.Stack())
+			Error(w, r, http.StatusInternalServerError, CodeInternal, "Internal server error")
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,160 @@
+// Package response writes v1 API responses. Every error goes out as
+//
+//	{"error": {"code": "...", "message": "...", "details": ..., "request_id": "..."}}
+//
+// so clients can branch on code and show message as-is.
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error identifier.
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidation       Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodePayloadTooLarge  Code = "payload_too_large"
+	CodeInternal         Code = "internal_error"
+	CodeUpstream         Code = "upstream_error"
+	CodeTimeout          Code = "timeout"
+	CodeUnavailable      Code = "unavailable"
+	CodeQuotaExceeded    Code = "quota_exceeded"
+	CodeContentBlocked   Code = "content_blocked"
+)
+
+type ErrorBody struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type Envelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// FieldError is one failed check, used as details for CodeValidation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// JSON writes v with the given status.
+func JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("⚠️ failed to write response: %v", err)
+	}
+}
+
+// OK is JSON with 200.
+func OK(w http.ResponseWriter, v any) {
+	JSON(w, http.StatusOK, v)
+}
+
+// Error writes the error envelope.
+func Error(w http.ResponseWriter, r *http.Request, status int, code Code, message string) {
+	ErrorDetails(w, r, status, code, message, nil)
+}
+
+func ErrorDetails(w http.ResponseWriter, r *http.Request, status int, code Code, message string, details any) {
+	JSON(w, status, Envelope{Error: ErrorBody{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: RequestIDFrom(r.Context()),
+	}})
+}
+
+func BadRequest(w http.ResponseWriter, r *http.Request, message string) {
+	Error(w, r, http.StatusBadRequest, CodeBadRequest, message)
+}
+
+func NotFound(w http.ResponseWriter, r *http.Request, message string) {
+	Error(w, r, http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(w http.ResponseWriter, r *http.Request, message string) {
+	Error(w, r, http.StatusConflict, CodeConflict, message)
+}
+
+// Internal logs err with the request ID and sends a generic message; the
+// cause never reaches the client.
+func Internal(w http.ResponseWriter, r *http.Request, message string, err error) {
+	log.Printf("❌ [%s] %s %s: %s: %v", RequestIDFrom(r.Context()), r.Method, r.URL.Path, message, err)
+	Error(w, r, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// Invalid writes a 422 listing every failed field.
+func Invalid(w http.ResponseWriter, r *http.Request, problems []FieldError) {
+	ErrorDetails(w, r, http.StatusUnprocessableEntity, CodeValidation, "Request validation failed", problems)
+}
+
+// Decode reads a JSON body into dst. On failure it has already written the
+// error response and returns false.
+func Decode(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decode(w, r, dst, false)
+}
+
+// DecodeOptional is Decode for bodies that may be left out: an empty body
+// leaves dst as it is.
+func DecodeOptional(w http.ResponseWriter, r *http.Request, dst any) bool {
+	return decode(w, r, dst, true)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst any, optional bool) bool {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if err == nil || (optional && errors.Is(err, io.EOF)) {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		Error(w, r, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit))
+	case errors.Is(err, io.EOF):
+		BadRequest(w, r, "Request body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		BadRequest(w, r, "Request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		Invalid(w, r, []FieldError{{Field: typeErr.Field, Message: "expected " + typeErr.Type.String()}})
+	default:
+		BadRequest(w, r, "Could not read request body")
+	}
+	return false
+}
+
+// Checks collects validation failures so a handler can report them together.
+type Checks []FieldError
+
+// Require records message for field when ok is false.
+func (c *Checks) Require(ok bool, field, message string) {
+	if !ok {
+		*c = append(*c, FieldError{Field: field, Message: message})
+	}
+}
+
+// Failed writes a 422 when any check failed and reports whether it did.
+func (c Checks) Failed(w http.ResponseWriter, r *http.Request) bool {
+	if len(c) == 0 {
+		return false
+	}
+	Invalid(w, r, c)
+	return true
+}
@@ -3,20 +3,22 @@ package network
 import (
 	"net/http"
 
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
 	v1 "bitbucket.org/Local/fpl-assistant/backend/internal/network/v1"
 	"github.com/go-chi/chi"
 )
 
-func NewRouter() http.Handler {
+func NewRouter(cfg *config.ServerConfig, deps v1.Deps) http.Handler {
 	r := chi.NewRouter()
+	r.Use(limitBody(cfg.MaxBodyBytes))
 
 	// Mount API versioned routes under /api/v1
 	r.Route("/v1", func(r chi.Router) {
-		r.Mount("/", v1.NewV1Router())
+		r.Mount("/", v1.NewV1Router(deps))
 	})
 
 	// Mount static file handler for React app (Vite)
-	makeStaticFileHandler(r)
+	makeStaticFileHandler(r, cfg)
 
 	return r
 }
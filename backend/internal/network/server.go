@@ -0,0 +1,61 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+)
+
+// Serve runs the HTTP server until ctx is cancelled, then stops accepting
+// connections and waits up to ShutdownTimeout for in-flight requests.
+//
+// Requests themselves aren't cancelled; AI handlers watch the same ctx (see
+// v1.Deps.Shutdown) so they don't hold the drain open.
+func Serve(ctx context.Context, cfg *config.ServerConfig, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		log.Printf("🛑 Shutting down, draining requests (up to %s)...", cfg.ShutdownTimeout)
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		shutdownDone <- srv.Shutdown(drainCtx)
+	}()
+
+	var err error
+	if cfg.TLS() {
+		log.Printf("🚀 Server running at https://%s", cfg.Address)
+		err = srv.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+	} else {
+		log.Printf("🚀 Server running at http://%s", cfg.Address)
+		err = srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdownDone
+}
+
+// limitBody caps request bodies; handlers see an error from Read once the
+// limit is exceeded.
+func limitBody(max int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, max)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,36 +1,172 @@
 package network
 
 import (
+	"io"
+	"io/fs"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/frontend"
 	"github.com/go-chi/chi"
 )
 
-func makeStaticFileHandler(r *chi.Mux) {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		log.Printf("❌ Failed to get working directory: %v", err)
+func makeStaticFileHandler(r *chi.Mux, cfg *config.ServerConfig) {
+	dist := frontendFS(cfg.StaticDir)
+	if dist == nil {
+		r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "frontend not built", http.StatusNotFound)
+		})
+		return
+	}
+
+	h := &spaHandler{dist: dist}
+	r.Get("/*", h.ServeHTTP)
+	r.Head("/*", h.ServeHTTP)
+	r.NotFound(h.ServeHTTP)
+}
+
+// frontendFS picks where the SPA comes from: an explicit static_dir wins
+// (dev), then the embedded build, then frontend/dist found on disk.
+func frontendFS(staticDir string) fs.FS {
+	if staticDir != "" {
+		if dir, ok := findDir(staticDir); ok {
+			log.Printf("📂 Serving frontend from %s", dir)
+			return os.DirFS(dir)
+		}
+		log.Printf("⚠️ static_dir %s not found", staticDir)
+		return nil
+	}
+	if dist, ok := frontend.Dist(); ok {
+		log.Println("📦 Serving embedded frontend")
+		return dist
+	}
+	if dir, ok := findDir(filepath.Join("frontend", "dist")); ok {
+		log.Printf("📂 Serving frontend from %s", dir)
+		return os.DirFS(dir)
+	}
+	log.Println("⚠️ No frontend build found (run `yarn build` or build with -tags embedfrontend)")
+	return nil
+}
+
+// findDir resolves a relative dir against the CWD, the project root above the
+// binary, and the project root above the CWD (go run from backend/cmd).
+func findDir(dir string) (string, bool) {
+	candidates := []string{dir}
+	if !filepath.IsAbs(dir) {
+		if exe, err := os.Executable(); err == nil {
+			candidates = append(candidates, filepath.Join(filepath.Dir(exe), "..", "..", dir))
+		}
+		if wd, err := os.Getwd(); err == nil {
+			candidates = append(candidates, filepath.Join(wd, "..", "..", dir))
+		}
+	}
+	for _, c := range candidates {
+		if st, err := os.Stat(c); err == nil && st.IsDir() {
+			abs, err := filepath.Abs(c)
+			if err != nil {
+				abs = c
+			}
+			return abs, true
+		}
+	}
+	return "", false
+}
+
+type spaHandler struct {
+	dist fs.FS
+}
+
+func (h *spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// API paths never fall back to index.html
+	if r.URL.Path == "/v1" || strings.HasPrefix(r.URL.Path, "/v1/") {
+		response.NotFound(w, r, "No such endpoint")
 		return
 	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	if !h.exists(name) {
+		// Missing files with an extension are real 404s (stale chunk, typo);
+		// anything else is a client-side route
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = "index.html"
+	}
+
+	switch {
+	case name == "index.html":
+		w.Header().Set("Cache-Control", "no-cache")
+	case strings.HasPrefix(name, "assets/"):
+		// Vite puts content hashes in these names
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	default:
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	}
+
+	h.serveFile(w, r, name)
+}
+
+func (h *spaHandler) exists(name string) bool {
+	st, err := fs.Stat(h.dist, name)
+	return err == nil && !st.IsDir()
+}
+
+// serveFile prefers a precompressed sibling (.br, then .gz) when the client
+// accepts it.
+func (h *spaHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	accept := r.Header.Get("Accept-Encoding")
 
-	projectRoot := filepath.Join(currentDir, "..", "..")
-	clientBuildDir := path.Join(projectRoot, "frontend", "dist")
-	clientStaticDir := path.Join(clientBuildDir, "assets")
+	file, encoding := name, ""
+	for _, enc := range []struct{ token, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if strings.Contains(accept, enc.token) && h.exists(name+enc.ext) {
+			file, encoding = name+enc.ext, enc.token
+			break
+		}
+	}
 
-	// Serve static files
-	r.Handle("/assets/*", http.StripPrefix("/assets/", http.FileServer(http.Dir(clientStaticDir))))
+	f, err := h.dist.Open(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
 
-	// Serve index.html for root
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, path.Join(clientBuildDir, "index.html"))
-	})
+	st, err := f.Stat()
+	if err != nil {
+		http.Error(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
 
-	// Fallback for SPA routes
-	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, path.Join(clientBuildDir, "index.html"))
-	})
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+		// ServeContent would sniff the compressed bytes otherwise
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		w.Header().Set("Content-Type", ctype)
+	}
+	http.ServeContent(w, r, path.Base(name), st.ModTime(), rs)
 }
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+func (h *Handler) SignupHandler(w http.ResponseWriter, r *http.Request) {
+	var req SignupRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	var checks response.Checks
+	checks.Require(validEmail(req.Email), "email", "must be a valid email address")
+	checks.Require(len(req.Password) >= 8, "password", "must be at least 8 characters")
+	// bcrypt ignores everything past 72 bytes
+	checks.Require(len(req.Password) <= 72, "password", "must be at most 72 bytes")
+	name := strings.TrimSpace(req.DisplayName)
+	checks.Require(name != "" && len(name) <= 100, "display_name", "must be 1-100 characters")
+	if checks.Failed(w, r) {
+		return
+	}
+
+	user, err := h.Sessions.Signup(r.Context(), req.Email, name, req.Password)
+	if errors.Is(err, auth.ErrEmailTaken) {
+		response.Conflict(w, r, "An account with this email already exists")
+		return
+	}
+	if err != nil {
+		response.Internal(w, r, "Failed to create account", err)
+		return
+	}
+	if err := h.Sessions.Start(w, r, user); err != nil {
+		response.Internal(w, r, "Failed to sign in", err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, toUserDTO(user))
+}
+
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	user, err := h.Sessions.Login(r.Context(), req.Email, req.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid email or password")
+		return
+	}
+	if err != nil {
+		response.Internal(w, r, "Failed to sign in", err)
+		return
+	}
+	if err := h.Sessions.Start(w, r, user); err != nil {
+		response.Internal(w, r, "Failed to sign in", err)
+		return
+	}
+	response.OK(w, toUserDTO(user))
+}
+
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.Sessions.End(w, r); err != nil {
+		response.Internal(w, r, "Failed to sign out", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) MeHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	response.OK(w, toUserDTO(user))
+}
+
+func validEmail(s string) bool {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && len(s) <= 254
+}
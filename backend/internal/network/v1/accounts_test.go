@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+func TestAccounts(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.client(t, "ann@example.com")
+
+	var me UserDTO
+	if status := call(t, c, http.MethodGet, api.URL+"/v1/auth/me", nil, &me); status != http.StatusOK || me.Email != "ann@example.com" {
+		t.Fatalf("GET /auth/me: status %d, %+v", status, me)
+	}
+
+	var env response.Envelope
+	status := call(t, http.DefaultClient, http.MethodPost, api.URL+"/v1/auth/signup",
+		SignupRequest{Email: "ANN@example.com", Password: "password123", DisplayName: "Ann"}, &env)
+	if status != http.StatusConflict {
+		t.Errorf("signup with a taken email: status %d, want 409", status)
+	}
+	status = call(t, http.DefaultClient, http.MethodPost, api.URL+"/v1/auth/login",
+		LoginRequest{Email: "ann@example.com", Password: "wrong-password"}, &env)
+	if status != http.StatusUnauthorized {
+		t.Errorf("login with a wrong password: status %d, want 401", status)
+	}
+
+	if status := call(t, c, http.MethodPost, api.URL+"/v1/auth/logout", nil, nil); status != http.StatusNoContent {
+		t.Errorf("logout: status %d", status)
+	}
+	if status := call(t, c, http.MethodGet, api.URL+"/v1/auth/me", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("GET /auth/me after logout: status %d, want 401", status)
+	}
+}
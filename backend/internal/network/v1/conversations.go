@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"github.com/go-chi/chi"
+)
+
+func (h *Handler) ListConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	convs, err := h.Store.Conversations.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch conversations", err)
+		return
+	}
+	out := make([]ConversationDTO, len(convs))
+	for i, c := range convs {
+		out[i] = toConversationDTO(c, nil)
+	}
+	response.OK(w, out)
+}
+
+func (h *Handler) CreateConversationHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	var req ConversationRequest // every field is optional, so is the body
+	if !response.DecodeOptional(w, r, &req) {
+		return
+	}
+	var checks response.Checks
+	title := strings.TrimSpace(req.Title)
+	checks.Require(len(title) <= 200, "title", "at most 200 characters")
+	if checks.Failed(w, r) {
+		return
+	}
+
+	conv := model.Conversation{UserID: user.ID, Title: title}
+	if err := h.Store.Conversations.Create(r.Context(), &conv); err != nil {
+		response.Internal(w, r, "Failed to create conversation", err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v1/conversations/%d", conv.ID))
+	response.JSON(w, http.StatusCreated, toConversationDTO(conv, nil))
+}
+
+func (h *Handler) GetConversationHandler(w http.ResponseWriter, r *http.Request) {
+	conv, ok := h.ownConversation(w, r)
+	if !ok {
+		return
+	}
+	msgs, err := h.Store.Conversations.Messages(r.Context(), conv.ID, 0)
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch messages", err)
+		return
+	}
+	response.OK(w, toConversationDTO(conv, msgs))
+}
+
+func (h *Handler) DeleteConversationHandler(w http.ResponseWriter, r *http.Request) {
+	conv, ok := h.ownConversation(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Store.Conversations.Delete(r.Context(), conv.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		response.Internal(w, r, "Failed to delete conversation", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PostMessageHandler asks the assistant within a conversation; earlier turns
+// are replayed so follow-ups keep their context.
+func (h *Handler) PostMessageHandler(w http.ResponseWriter, r *http.Request) {
+	h.extendWriteDeadline(w)
+	conv, ok := h.ownConversation(w, r)
+	if !ok {
+		return
+	}
+	var req AskRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	var checks response.Checks
+	message := h.cleanInput(&checks, "message", req.Message, true)
+	if req.Squad != nil {
+		checkAskSquad(&checks, req.Squad)
+	}
+	if checks.Failed(w, r) {
+		return
+	}
+
+	c := caller(r)
+	if !h.allowAI(w, r, c) {
+		return
+	}
+
+	ctx, cancel := h.aiContext(r)
+	defer cancel()
+	turn, err := h.Chat.Reply(ctx, &conv, message, req.Squad.toSquad())
+	var storeErr *assistant.StorageError
+	if errors.As(err, &storeErr) {
+		response.Internal(w, r, "Failed to save the conversation", err)
+		return
+	}
+	if err != nil {
+		writeAIError(w, r, h.AI.Name(), err)
+		return
+	}
+	h.Meter.Record(r.Context(), c, "conversation", turn.Prompt, turn.Response, false)
+	response.OK(w, ConversationReplyDTO{
+		Question: toMessageDTO(turn.Question),
+		Reply:    toMessageDTO(turn.Answer),
+		Model:    turn.Response.Model,
+		Prompt:   turn.Prompt,
+	})
+}
+
+// ownConversation loads {id} and checks it belongs to the signed-in user,
+// answering 404 otherwise like ownSquad.
+func (h *Handler) ownConversation(w http.ResponseWriter, r *http.Request) (model.Conversation, bool) {
+	user, _ := auth.UserFrom(r.Context())
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.NotFound(w, r, "Conversation not found")
+		return model.Conversation{}, false
+	}
+	conv, err := h.Store.Conversations.Get(r.Context(), uint(id))
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && conv.UserID != user.ID) {
+		response.NotFound(w, r, "Conversation not found")
+		return model.Conversation{}, false
+	}
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch conversation", err)
+		return model.Conversation{}, false
+	}
+	return conv, true
+}
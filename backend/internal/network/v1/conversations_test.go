@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConversations(t *testing.T) {
+	api := newTestAPI(t)
+	ann := api.client(t, "ann@example.com")
+	bob := api.client(t, "bob@example.com")
+
+	var conv ConversationDTO
+	if status := call(t, ann, http.MethodPost, api.URL+"/v1/conversations", ConversationRequest{}, &conv); status != http.StatusCreated {
+		t.Fatalf("create: status %d", status)
+	}
+	url := fmt.Sprintf("%s/v1/conversations/%d", api.URL, conv.ID)
+
+	var reply ConversationReplyDTO
+	status := call(t, ann, http.MethodPost, url+"/messages", AskRequest{Message: "Is Salah worth it?"}, &reply)
+	if status != http.StatusOK || reply.Question.Content != "Is Salah worth it?" || !strings.Contains(reply.Reply.Content, "Salah") {
+		t.Fatalf("post message: status %d, %+v", status, reply)
+	}
+
+	var got ConversationDTO
+	call(t, ann, http.MethodGet, url, nil, &got)
+	if got.Title != "Is Salah worth it?" || len(got.Messages) != 2 || got.Messages[1].Role != "assistant" {
+		t.Errorf("GET after one turn: %+v", got)
+	}
+
+	// another user's conversation looks like it doesn't exist
+	if status := call(t, bob, http.MethodGet, url, nil, nil); status != http.StatusNotFound {
+		t.Errorf("GET by another user: status %d, want 404", status)
+	}
+	if status := call(t, bob, http.MethodPost, url+"/messages", AskRequest{Message: "Hi"}, nil); status != http.StatusNotFound {
+		t.Errorf("post by another user: status %d, want 404", status)
+	}
+	if status := call(t, bob, http.MethodDelete, url, nil, nil); status != http.StatusNotFound {
+		t.Errorf("DELETE by another user: status %d, want 404", status)
+	}
+	if status := call(t, http.DefaultClient, http.MethodGet, url, nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("GET signed out: status %d, want 401", status)
+	}
+
+	if status := call(t, ann, http.MethodDelete, url, nil, nil); status != http.StatusNoContent {
+		t.Errorf("owner DELETE: status %d", status)
+	}
+}
+
+func TestCreateConversationWithoutBody(t *testing.T) {
+	api := newTestAPI(t)
+	ann := api.client(t, "ann@example.com")
+
+	var conv ConversationDTO
+	if status := call(t, ann, http.MethodPost, api.URL+"/v1/conversations", nil, &conv); status != http.StatusCreated || conv.ID == 0 {
+		t.Errorf("no body: status %d, %+v", status, conv)
+	}
+	resp, err := ann.Post(api.URL+"/v1/conversations", "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("broken JSON: status %d, want 400", resp.StatusCode)
+	}
+}
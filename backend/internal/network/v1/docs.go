@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/assets"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/openapi"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+// buildSpec renders the OpenAPI document for the route table once at startup.
+func buildSpec(routes []route) []byte {
+	b := &openapi.Builder{
+		Info: openapi.Info{
+			Title:       "FPL Assistant API",
+			Version:     "1",
+			Description: "Errors always use the envelope {error: {code, message, details, request_id}}.",
+		},
+		BasePath:   "/v1",
+		ErrorModel: response.Envelope{},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			"apiKey":  {Type: "apiKey", In: "header", Name: auth.APIKeyHeader},
+			"bearer":  {Type: "http", Scheme: "bearer", Description: "The same API key as a bearer token"},
+			"session": {Type: "apiKey", In: "cookie", Name: auth.SessionCookie, Description: "Set by /auth/login"},
+		},
+	}
+	for _, rt := range routes {
+		op := rt.Operation
+		switch rt.role {
+		case "":
+		case auth.RoleUser:
+			op.Security = []string{"session"}
+			op.Errors = append([]int{http.StatusUnauthorized}, op.Errors...)
+		case auth.RoleGuest:
+			// works signed out; the cookie only ties the call to a user
+			op.Security = []string{"session"}
+			op.AuthOptional = true
+		default:
+			op.Security = []string{"apiKey", "bearer"}
+			op.Errors = append([]int{http.StatusUnauthorized, http.StatusForbidden}, op.Errors...)
+			op.Description = strings.TrimSpace(op.Description + " Requires the " + string(rt.role) + " role.")
+		}
+		b.Add(op)
+	}
+	spec, err := json.MarshalIndent(b.Build(), "", "  ")
+	if err != nil {
+		// Only possible if a DTO has an unmarshalable type; fail loud in dev
+		log.Panicf("❌ failed to build OpenAPI spec: %v", err)
+	}
+	return spec
+}
+
+func serveSpec(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// Redoc page; the script comes from the CDN so nothing is vendored.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>FPL Assistant API</title>
+  <meta charset="utf-8"/>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="/v1/openapi.json"></redoc>
+  <script src="%s"></script>
+</body>
+</html>
+`
+
+const (
+	redocFile = "redoc/redoc.standalone.js"
+	redocCDN  = "https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"
+)
+
+// docsHandlers serves the docs page and the Redoc script vendored into
+// assets. Builds without it (fetchRedoc.sh never ran) fall back to the CDN.
+func docsHandlers() (page, script http.HandlerFunc) {
+	js, err := assets.Redoc.ReadFile(redocFile)
+	src := "/v1/docs/redoc.js"
+	if err != nil {
+		log.Printf("⚠️ %s is not vendored, /v1/docs loads it from the CDN (run go generate ./assets)", redocFile)
+		src = redocCDN
+	}
+	html := []byte(fmt.Sprintf(docsPage, src))
+
+	page = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(html)
+	}
+	script = func(w http.ResponseWriter, r *http.Request) {
+		if js == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write(js)
+	}
+	return page, script
+}
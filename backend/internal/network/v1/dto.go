@@ -0,0 +1,428 @@
+package v1
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+)
+
+// Response shapes for the v1 API. They are kept apart from the GORM models
+// so table changes don't leak into the JSON, and every field is snake_case.
+
+type AskRequest struct {
+	Message string    `json:"message" doc:"At most ai.max_input_chars characters; chat-template markers are stripped"`
+	Squad   *AskSquad `json:"squad,omitempty" doc:"The squad on screen; answers are grounded in it when present"`
+}
+
+type AskSquad struct {
+	Starters   []uint  `json:"starters" doc:"Player ids, at most 11"`
+	Bench      []uint  `json:"bench" doc:"Player ids"`
+	CaptainID  uint    `json:"captain_id,omitempty"`
+	ViceID     uint    `json:"vice_id,omitempty"`
+	BudgetLeft float64 `json:"budget_left" doc:"In £m"`
+}
+
+type AskResponse struct {
+	Reply  string         `json:"reply"`
+	Model  string         `json:"model" doc:"Model that produced the reply"`
+	Tools  []ToolTraceDTO `json:"tools,omitempty" doc:"Database lookups the model made, in order"`
+	Cached bool           `json:"cached" doc:"Answered from the cache; doesn't count against the daily quota"`
+	Prompt string         `json:"prompt_version" doc:"Prompt templates used, e.g. \"persona@1 squad@2\""`
+}
+
+type ToolTraceDTO struct {
+	Name       string          `json:"name" enum:"search_players,get_player_fixtures,compare_players,check_squad"`
+	Args       json.RawMessage `json:"args"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS float64         `json:"duration_ms"`
+}
+
+type StreamDeltaDTO struct {
+	Text string `json:"text"`
+}
+
+type TransferSuggestRequest struct {
+	Squad         AskSquad `json:"squad"`
+	FreeTransfers *int     `json:"free_transfers,omitempty" doc:"Default 1; each extra transfer costs 4 points"`
+	Notes         string   `json:"notes,omitempty" doc:"Preferences, e.g. \"keep Salah\""`
+}
+
+type PlayerRefDTO struct {
+	ID            uint    `json:"id"`
+	WebName       string  `json:"web_name"`
+	TeamShortName string  `json:"team_short_name"`
+	Position      string  `json:"position" enum:"GK,DEF,MID,FWD"`
+	Price         float64 `json:"price"`
+}
+
+type TransferDTO struct {
+	Out PlayerRefDTO `json:"out"`
+	In  PlayerRefDTO `json:"in"`
+}
+
+type TransferSuggestionDTO struct {
+	Transfers  []TransferDTO `json:"transfers"`
+	Captain    *PlayerRefDTO `json:"captain"`
+	Reasoning  string        `json:"reasoning"`
+	BudgetLeft float64       `json:"budget_left" doc:"£m left after the transfers"`
+	PointsHit  int           `json:"points_hit" doc:"4 per transfer beyond the free ones"`
+	Attempts   int           `json:"attempts" doc:"Model answers needed to get a valid one"`
+	Model      string        `json:"model"`
+	Prompt     string        `json:"prompt_version"`
+}
+
+type AIUsageTotalsDTO struct {
+	Key              string `json:"key,omitempty" doc:"Day (YYYY-MM-DD, UTC), endpoint, model, or user:<id> / client:<ip>"`
+	Requests         int    `json:"requests"`
+	Cached           int    `json:"cached"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+type AIUsageReportDTO struct {
+	Since      time.Time          `json:"since"`
+	Until      time.Time          `json:"until"`
+	DailyQuota int                `json:"daily_quota" doc:"Uncached requests per caller per UTC day; 0 = unlimited"`
+	Total      AIUsageTotalsDTO   `json:"total"`
+	ByDay      []AIUsageTotalsDTO `json:"by_day"`
+	ByEndpoint []AIUsageTotalsDTO `json:"by_endpoint"`
+	ByModel    []AIUsageTotalsDTO `json:"by_model"`
+	ByPrompt   []AIUsageTotalsDTO `json:"by_prompt" doc:"Per prompt template version"`
+	ByCaller   []AIUsageTotalsDTO `json:"by_caller" doc:"Heaviest token users first"`
+}
+
+type PromptRenderRequest struct {
+	Squad         *AskSquad `json:"squad,omitempty" doc:"Default: a sample in-form squad"`
+	FreeTransfers *int      `json:"free_transfers,omitempty" doc:"Default 1"`
+	Tools         bool      `json:"tools,omitempty" doc:"Render as for a model with tool calls"`
+}
+
+type PromptRenderDTO struct {
+	Name    string `json:"name"`
+	Version string `json:"version" doc:"e.g. squad@2"`
+	Text    string `json:"text"`
+}
+
+type ConversationRequest struct {
+	Title string `json:"title" doc:"Optional; defaults to the first question"`
+}
+
+type MessageDTO struct {
+	ID        uint      `json:"id"`
+	Role      string    `json:"role" enum:"user,assistant"`
+	Content   string    `json:"content"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConversationDTO struct {
+	ID        uint         `json:"id"`
+	Title     string       `json:"title"`
+	Summary   string       `json:"summary,omitempty" doc:"Summary of older turns no longer replayed verbatim"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Messages  []MessageDTO `json:"messages,omitempty" doc:"Only included when fetching a single conversation"`
+}
+
+type ConversationReplyDTO struct {
+	Question MessageDTO `json:"question"`
+	Reply    MessageDTO `json:"reply"`
+	Model    string     `json:"model"`
+	Prompt   string     `json:"prompt_version"`
+}
+
+type GameweekReportDTO struct {
+	Gameweek    int        `json:"gameweek"`
+	Markdown    string     `json:"markdown"`
+	Model       string     `json:"model"`
+	Prompt      string     `json:"prompt_version"`
+	DataAsOf    *time.Time `json:"data_as_of,omitempty" doc:"FPL import the report was written from"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+type JobAcceptedDTO struct {
+	JobID string `json:"job_id"`
+}
+
+type SignupRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password" doc:"8-72 characters"`
+	DisplayName string `json:"display_name"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type UserDTO struct {
+	ID          uint      `json:"id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SquadPickDTO struct {
+	PlayerID   uint `json:"player_id"`
+	IsCaptain  bool `json:"is_captain"`
+	IsVice     bool `json:"is_vice"`
+	IsStarting bool `json:"is_starting"`
+}
+
+type SquadRequest struct {
+	Name   string         `json:"name"`
+	Points int            `json:"points"`
+	Picks  []SquadPickDTO `json:"picks" doc:"Up to 15 players, at most 11 starting"`
+}
+
+type SquadDTO struct {
+	ID     uint           `json:"id"`
+	Name   string         `json:"name"`
+	Points int            `json:"points"`
+	Picks  []SquadPickDTO `json:"picks,omitempty" doc:"Only included when fetching a single squad"`
+}
+
+type PlayerDTO struct {
+	ID                uint      `json:"id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	WebName           string    `json:"web_name"`
+	TeamID            uint      `json:"team_id"`
+	TeamShortName     string    `json:"team_short_name"`
+	Position          string    `json:"position" enum:"GK,DEF,MID,FWD"`
+	Price             float64   `json:"price" doc:"Current price in £m"`
+	StartPrice        float64   `json:"start_price"`
+	SelectedByPercent float64   `json:"selected_by_percent"`
+	TransfersIn       int       `json:"transfers_in"`
+	TransfersInEvent  int       `json:"transfers_in_event"`
+	TransfersOut      int       `json:"transfers_out"`
+	TransfersOutEvent int       `json:"transfers_out_event"`
+	EventPoints       int       `json:"event_points"`
+	TotalPoints       int       `json:"total_points"`
+	Form              float64   `json:"form"`
+	ValueForm         float64   `json:"value_form"`
+	IctIndex          float64   `json:"ict_index"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	PrettyUpdatedAt   string    `json:"pretty_updated_at" doc:"e.g. Sat 14 Sep 11:30 UTC"`
+}
+
+type TeamDTO struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	ShortName string `json:"short_name"`
+	Code      string `json:"code"`
+}
+
+type FixtureDTO struct {
+	ID                   int        `json:"id"`
+	Event                *int       `json:"event" doc:"Gameweek, null while unscheduled"`
+	KickoffTime          *time.Time `json:"kickoff_time"`
+	ProvisionalStartTime bool       `json:"provisional_start_time"`
+	Started              bool       `json:"started"`
+	Finished             bool       `json:"finished"`
+	Minutes              int        `json:"minutes"`
+	TeamH                uint       `json:"team_h"`
+	TeamHShortName       string     `json:"team_h_short_name"`
+	TeamHScore           *int       `json:"team_h_score"`
+	TeamHDifficulty      int        `json:"team_h_difficulty"`
+	TeamA                uint       `json:"team_a"`
+	TeamAShortName       string     `json:"team_a_short_name"`
+	TeamAScore           *int       `json:"team_a_score"`
+	TeamADifficulty      int        `json:"team_a_difficulty"`
+}
+
+type ChangeEventDTO struct {
+	ID         uint      `json:"id"`
+	Kind       string    `json:"kind"`
+	EntityType string    `json:"entity_type"`
+	EntityID   uint      `json:"entity_id"`
+	OldValue   string    `json:"old_value,omitempty"`
+	NewValue   string    `json:"new_value,omitempty"`
+	Summary    string    `json:"summary"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// teamShortNames maps team id -> short name for embedding in DTOs.
+func teamShortNames(teams []model.Team) map[uint]string {
+	names := make(map[uint]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.ShortName
+	}
+	return names
+}
+
+func toPlayerDTO(p model.Player, teamNames map[uint]string) PlayerDTO {
+	return PlayerDTO{
+		ID:                p.ID,
+		FirstName:         p.FirstName,
+		LastName:          p.LastName,
+		WebName:           p.WebName,
+		TeamID:            p.TeamID,
+		TeamShortName:     teamNames[p.TeamID],
+		Position:          p.Position,
+		Price:             p.CurrentPrice,
+		StartPrice:        p.StartPrice,
+		SelectedByPercent: p.SelectedByPercent,
+		TransfersIn:       p.TransfersIn,
+		TransfersInEvent:  p.TransfersInEvent,
+		TransfersOut:      p.TransfersOut,
+		TransfersOutEvent: p.TransfersOutEvent,
+		EventPoints:       p.EventPoints,
+		TotalPoints:       p.TotalPoints,
+		Form:              parseFloat(p.Form),
+		ValueForm:         p.ValueForm,
+		IctIndex:          parseFloat(p.IctIndex),
+		UpdatedAt:         p.UpdatedAt,
+		PrettyUpdatedAt:   prettyTime(p.UpdatedAt),
+	}
+}
+
+func toPlayerDTOs(players []model.Player, teamNames map[uint]string) []PlayerDTO {
+	out := make([]PlayerDTO, len(players))
+	for i, p := range players {
+		out[i] = toPlayerDTO(p, teamNames)
+	}
+	return out
+}
+
+func toTeamDTOs(teams []model.Team) []TeamDTO {
+	out := make([]TeamDTO, len(teams))
+	for i, t := range teams {
+		out[i] = TeamDTO{ID: t.ID, Name: t.Name, ShortName: t.ShortName, Code: t.Code}
+	}
+	return out
+}
+
+func toFixtureDTOs(fixtures []model.Fixture, teamNames map[uint]string) []FixtureDTO {
+	out := make([]FixtureDTO, len(fixtures))
+	for i, f := range fixtures {
+		out[i] = FixtureDTO{
+			ID:                   f.ID,
+			Event:                f.Event,
+			KickoffTime:          f.KickoffTime,
+			ProvisionalStartTime: f.ProvisionalStartTime,
+			Started:              f.Started,
+			Finished:             f.Finished,
+			Minutes:              f.Minutes,
+			TeamH:                f.TeamHID,
+			TeamHShortName:       teamNames[f.TeamHID],
+			TeamHScore:           f.TeamHScore,
+			TeamHDifficulty:      f.TeamHDifficulty,
+			TeamA:                f.TeamAID,
+			TeamAShortName:       teamNames[f.TeamAID],
+			TeamAScore:           f.TeamAScore,
+			TeamADifficulty:      f.TeamADifficulty,
+		}
+	}
+	return out
+}
+
+func toChangeEventDTOs(events []model.ChangeEvent) []ChangeEventDTO {
+	out := make([]ChangeEventDTO, len(events))
+	for i, e := range events {
+		out[i] = ChangeEventDTO(e)
+	}
+	return out
+}
+
+func toPlayerRefDTO(p model.Player, teamNames map[uint]string) PlayerRefDTO {
+	return PlayerRefDTO{ID: p.ID, WebName: p.WebName, TeamShortName: teamNames[p.TeamID], Position: p.Position, Price: p.CurrentPrice}
+}
+
+func toTransferSuggestionDTO(s assistant.Suggestion, teamNames map[uint]string) TransferSuggestionDTO {
+	dto := TransferSuggestionDTO{
+		Transfers: []TransferDTO{}, Reasoning: s.Reasoning, BudgetLeft: s.BudgetLeft,
+		PointsHit: s.PointsHit, Attempts: s.Attempts, Model: s.Model, Prompt: s.Prompt,
+	}
+	for _, t := range s.Transfers {
+		dto.Transfers = append(dto.Transfers, TransferDTO{Out: toPlayerRefDTO(t.Out, teamNames), In: toPlayerRefDTO(t.In, teamNames)})
+	}
+	if s.Captain != nil {
+		c := toPlayerRefDTO(*s.Captain, teamNames)
+		dto.Captain = &c
+	}
+	return dto
+}
+
+func toAIUsageTotalsDTOs(in []assistant.UsageTotals) []AIUsageTotalsDTO {
+	out := make([]AIUsageTotalsDTO, len(in))
+	for i, t := range in {
+		out[i] = AIUsageTotalsDTO(t)
+	}
+	return out
+}
+
+func toToolTraceDTOs(trace []assistant.ToolTrace) []ToolTraceDTO {
+	if len(trace) == 0 {
+		return nil
+	}
+	out := make([]ToolTraceDTO, len(trace))
+	for i, t := range trace {
+		out[i] = ToolTraceDTO{
+			Name: t.Name, Args: t.Args, Result: t.Result, Error: t.Error,
+			DurationMS: float64(t.Duration.Microseconds()) / 1000,
+		}
+	}
+	return out
+}
+
+func (s *AskSquad) toSquad() *assistant.Squad {
+	if s == nil {
+		return nil
+	}
+	return &assistant.Squad{
+		Starters: s.Starters, Bench: s.Bench, CaptainID: s.CaptainID, ViceID: s.ViceID, BudgetLeft: s.BudgetLeft,
+	}
+}
+
+func toGameweekReportDTO(r model.GameweekReport) GameweekReportDTO {
+	dto := GameweekReportDTO{Gameweek: r.Gameweek, Markdown: r.Markdown, Model: r.Model,
+		Prompt: r.PromptVersion, GeneratedAt: r.UpdatedAt}
+	if !r.DataAsOf.IsZero() {
+		dto.DataAsOf = &r.DataAsOf
+	}
+	return dto
+}
+
+func toMessageDTO(m model.ConversationMessage) MessageDTO {
+	return MessageDTO{ID: m.ID, Role: m.Role, Content: m.Content, Model: m.Model, CreatedAt: m.CreatedAt}
+}
+
+func toConversationDTO(c model.Conversation, msgs []model.ConversationMessage) ConversationDTO {
+	dto := ConversationDTO{ID: c.ID, Title: c.Title, Summary: c.Summary, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt}
+	for _, m := range msgs {
+		dto.Messages = append(dto.Messages, toMessageDTO(m))
+	}
+	return dto
+}
+
+func toUserDTO(u model.User) UserDTO {
+	return UserDTO{ID: u.ID, Email: u.Email, DisplayName: u.DisplayName, CreatedAt: u.CreatedAt}
+}
+
+func toSquadDTO(t model.UserTeam, picks []model.UserTeamPlayer) SquadDTO {
+	dto := SquadDTO{ID: t.ID, Name: t.UserName, Points: t.Points}
+	for _, p := range picks {
+		dto.Picks = append(dto.Picks, SquadPickDTO{
+			PlayerID: p.PlayerID, IsCaptain: p.IsCaptain, IsVice: p.IsVice, IsStarting: p.IsStarting,
+		})
+	}
+	return dto
+}
+
+// FPL sends form and ICT as strings ("5.3"); blanks become 0.
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func prettyTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("Mon 2 Jan 15:04 MST")
+}
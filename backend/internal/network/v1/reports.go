@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"github.com/go-chi/chi"
+)
+
+// GetReportHandler serves the stored preview for {gw}.
+func (h *Handler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	gw, err := strconv.Atoi(chi.URLParam(r, "gw"))
+	if err != nil || gw < 1 {
+		response.NotFound(w, r, "Report not found")
+		return
+	}
+	rep, err := h.Store.Reports.Get(r.Context(), gw)
+	if errors.Is(err, repository.ErrNotFound) {
+		response.NotFound(w, r, "No report for this gameweek yet")
+		return
+	}
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch report", err)
+		return
+	}
+	response.OK(w, toGameweekReportDTO(rep))
+}
+
+// GenerateReportHandler (re)writes the next gameweek's report in the
+// background, replacing one that already exists.
+func (h *Handler) GenerateReportHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.Reporter.SubmitReport(h.Jobs, "api", true)
+	if err != nil && !errors.Is(err, jobs.ErrAlreadyRunning) {
+		response.Internal(w, r, "Failed to start gameweek report", err)
+		return
+	}
+
+	id := job.Snapshot().ID
+	w.Header().Set("Location", "/v1/admin/jobs/"+id)
+	if err != nil {
+		response.ErrorDetails(w, r, http.StatusConflict, response.CodeConflict,
+			"A gameweek report is already being written", map[string]string{"job_id": id})
+		return
+	}
+	response.JSON(w, http.StatusAccepted, JobAcceptedDTO{JobID: id})
+}
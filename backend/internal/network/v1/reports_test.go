@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+)
+
+func TestReports(t *testing.T) {
+	api := newTestAPI(t)
+
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/reports/10", nil, nil); status != http.StatusNotFound {
+		t.Errorf("GET before any report: status %d, want 404", status)
+	}
+	if status := call(t, http.DefaultClient, http.MethodPost, api.URL+"/v1/admin/reports", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("generate without a key: status %d, want 401", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, api.URL+"/v1/admin/reports", nil)
+	req.Header.Set(auth.APIKeyHeader, testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("generate: status %d, want 202", resp.StatusCode)
+	}
+
+	var rep GameweekReportDTO
+	deadline := time.Now().Add(5 * time.Second)
+	for call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/reports/10", nil, &rep) != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("the GW10 report was never written")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if rep.Gameweek != 10 || rep.Model != "fake" || rep.Markdown == "" {
+		t.Errorf("GET /reports/10: %+v", rep)
+	}
+}
+
+func TestGetReportBadGameweek(t *testing.T) {
+	api := newTestAPI(t)
+	api.store.Reports.Save(context.Background(), &model.GameweekReport{Gameweek: 1, Markdown: "# GW1"})
+
+	for _, gw := range []string{"0", "-1", "one"} {
+		if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/reports/"+gw, nil, nil); status != http.StatusNotFound {
+			t.Errorf("GET /reports/%s: status %d, want 404", gw, status)
+		}
+	}
+	var rep GameweekReportDTO
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/reports/1", nil, &rep); status != http.StatusOK || rep.Markdown != "# GW1" {
+		t.Errorf("GET /reports/1: status %d, %+v", status, rep)
+	}
+}
@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"net/http"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/openapi"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/scheduler"
+)
+
+// route pairs a handler with its OpenAPI description. NewV1Router mounts
+// exactly this table and /openapi.json is built from it, so every new
+// endpoint goes here.
+type route struct {
+	openapi.Operation
+	handler http.HandlerFunc
+	role    auth.Role // API key role, auth.RoleUser for a session, "" for public
+}
+
+func (h *Handler) routes() []route {
+	return []route{
+		// Admin
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/admin/import-fpl", Tag: "admin",
+			Summary:     "Start an FPL import",
+			Description: "Queues a background import and returns its job id. 409 carries the id of the import already running in error.details.job_id.",
+			Response:    JobAcceptedDTO{}, Status: http.StatusAccepted,
+			Errors: []int{http.StatusConflict, http.StatusInternalServerError},
+		}, h.ImportFPLHandler, auth.RoleAdmin},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/admin/reports", Tag: "admin",
+			Summary: "Write the next gameweek's report",
+			Description: "Queues a job that asks the AI for the preview of the next gameweek, replacing any report it already has. " +
+				"Reports are also written automatically after an import once the gameweek's fixtures are known, " +
+				"and rewritten when an import brings data newer than the report's `data_as_of`. " +
+				"409 carries the id of the job already running in error.details.job_id.",
+			Response: JobAcceptedDTO{}, Status: http.StatusAccepted,
+			Errors: []int{http.StatusConflict, http.StatusInternalServerError},
+		}, h.GenerateReportHandler, auth.RoleAdmin},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/sync-status", Tag: "admin",
+			Summary:  "Scheduled sync status",
+			Response: scheduler.Status{},
+		}, h.SyncStatusHandler, auth.RoleViewer},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/jobs", Tag: "admin",
+			Summary:  "List recent background jobs",
+			Response: []jobs.Snapshot{},
+		}, h.ListJobsHandler, auth.RoleViewer},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/jobs/{id}", Tag: "admin",
+			Summary:  "Get a background job",
+			Response: jobs.Snapshot{},
+			Errors:   []int{http.StatusNotFound},
+		}, h.GetJobHandler, auth.RoleViewer},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/admin/jobs/{id}", Tag: "admin",
+			Summary: "Cancel a background job",
+			Status:  http.StatusAccepted,
+			Errors:  []int{http.StatusNotFound, http.StatusConflict},
+		}, h.CancelJobHandler, auth.RoleAdmin},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/ai-usage", Tag: "admin",
+			Summary:     "AI requests and token usage",
+			Description: "Totals per day, endpoint, model and caller. Cache hits count as requests with no tokens.",
+			Params: []openapi.Param{
+				openapi.Q("since", "string", "RFC3339 timestamp, default midnight UTC 6 days ago"),
+				openapi.Q("until", "string", "RFC3339 timestamp, default now"),
+			},
+			Response: AIUsageReportDTO{},
+			Errors:   []int{http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		}, h.AIUsageHandler, auth.RoleViewer},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/admin/prompts", Tag: "admin",
+			Summary:  "List prompt templates with their versions",
+			Response: []prompts.Info{},
+		}, h.ListPromptsHandler, auth.RoleViewer},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/admin/prompts/{name}/render", Tag: "admin",
+			Summary: "Render a prompt template with live data",
+			Description: "For checking template edits (see `ai.prompts_dir`); nothing is sent to the model. " +
+				"The body is optional; without a squad an in-form sample squad is used.",
+			Request: PromptRenderRequest{}, Response: PromptRenderDTO{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		}, h.RenderPromptHandler, auth.RoleViewer},
+
+		// Accounts
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/auth/signup", Tag: "accounts",
+			Summary: "Create an account and sign in",
+			Request: SignupRequest{}, Response: UserDTO{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		}, h.SignupHandler, ""},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/auth/login", Tag: "accounts",
+			Summary:     "Sign in",
+			Description: "Sets the " + auth.SessionCookie + " HttpOnly cookie.",
+			Request:     LoginRequest{}, Response: UserDTO{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, h.LoginHandler, ""},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/auth/logout", Tag: "accounts",
+			Summary: "Sign out",
+			Status:  http.StatusNoContent,
+		}, h.LogoutHandler, ""},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/auth/me", Tag: "accounts",
+			Summary:  "Current user",
+			Response: UserDTO{},
+		}, h.MeHandler, auth.RoleUser},
+
+		// Squads, each visible to its owner only
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/squads", Tag: "squads",
+			Summary:  "List my squads",
+			Response: []SquadDTO{},
+		}, h.ListSquadsHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/squads", Tag: "squads",
+			Summary: "Create a squad",
+			Request: SquadRequest{}, Response: SquadDTO{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		}, h.CreateSquadHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/squads/{id}", Tag: "squads",
+			Summary:  "Get one of my squads with its picks",
+			Response: SquadDTO{},
+			Errors:   []int{http.StatusNotFound},
+		}, h.GetSquadHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/squads/{id}", Tag: "squads",
+			Summary: "Replace one of my squads",
+			Request: SquadRequest{}, Response: SquadDTO{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity},
+		}, h.UpdateSquadHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/squads/{id}", Tag: "squads",
+			Summary: "Delete one of my squads",
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusNotFound},
+		}, h.DeleteSquadHandler, auth.RoleUser},
+
+		// AI
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/ask-ai", Tag: "ai",
+			Summary: "Ask the AI assistant",
+			Description: "Answers are grounded in current prices, form and fixtures. Send `squad` to talk about your own team. " +
+				"Unless `ai.max_tool_steps` is 0 the model can query players, fixtures and squad rules itself; those calls are listed in `tools`. " +
+				"Repeated questions are answered from a cache until the next FPL import; other requests count against `ai.daily_quota` (429 once used up). " +
+				"Questions the provider refuses on safety grounds get a 422 with code `content_blocked`.",
+			Request: AskRequest{}, Response: AskResponse{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge,
+				http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		}, h.AskAIHandler, auth.RoleGuest},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/ask-ai/stream", Tag: "ai",
+			Summary: "Ask the AI assistant, streaming the reply",
+			Description: "Same request as /ask-ai. Answers `text/event-stream` with `delta` events ({text}), then one `done` event " +
+				"(the /ask-ai response) or an `error` event (the error body). Failures before the first delta are ordinary JSON errors.",
+			Request: AskRequest{}, Response: StreamDeltaDTO{}, ResponseType: "text/event-stream",
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge,
+				http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		}, h.AskAIStreamHandler, auth.RoleGuest},
+
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/transfers/suggest", Tag: "ai",
+			Summary: "Suggest transfers and a captain for a squad",
+			Description: "The model answers in JSON; players are resolved by id or web name and the result is checked " +
+				"against prices, budget and the 3-per-club rule. Invalid answers are sent back for correction up to 3 times; " +
+				"if all fail the 502 lists the remaining problems in `details`.",
+			Request: TransferSuggestRequest{}, Response: TransferSuggestionDTO{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity,
+				http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		}, h.SuggestTransfersHandler, auth.RoleGuest},
+
+		// Conversations, each visible to its owner only
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/conversations", Tag: "ai",
+			Summary:  "List my conversations, most recent first",
+			Response: []ConversationDTO{},
+		}, h.ListConversationsHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/conversations", Tag: "ai",
+			Summary: "Start a conversation",
+			Request: ConversationRequest{}, RequestOptional: true, Response: ConversationDTO{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		}, h.CreateConversationHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/conversations/{id}", Tag: "ai",
+			Summary:  "Get one of my conversations with its messages",
+			Response: ConversationDTO{},
+			Errors:   []int{http.StatusNotFound},
+		}, h.GetConversationHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/conversations/{id}", Tag: "ai",
+			Summary: "Delete one of my conversations",
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusNotFound},
+		}, h.DeleteConversationHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/conversations/{id}/messages", Tag: "ai",
+			Summary:     "Ask the assistant within a conversation",
+			Description: "Earlier turns are replayed as context; once they outgrow `ai.history_tokens` the oldest are summarized.",
+			Request:     AskRequest{}, Response: ConversationReplyDTO{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity,
+				http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		}, h.PostMessageHandler, auth.RoleUser},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/reports/{gw}", Tag: "ai",
+			Summary:     "AI-written preview of a gameweek",
+			Description: "Markdown covering fixtures, captain picks, transfer trends and differentials, written per gameweek and refreshed when an import brings newer data.",
+			Response:    GameweekReportDTO{},
+			Errors:      []int{http.StatusNotFound, http.StatusInternalServerError},
+		}, h.GetReportHandler, ""},
+
+		// FPL data
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/players", Tag: "fpl",
+			Summary:  "List all players",
+			Response: []PlayerDTO{},
+			Errors:   []int{http.StatusInternalServerError},
+		}, h.GetAllPlayers, ""},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/teams", Tag: "fpl",
+			Summary:  "List all teams",
+			Response: []TeamDTO{},
+			Errors:   []int{http.StatusInternalServerError},
+		}, h.GetAllTeams, ""},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/fixtures", Tag: "fpl",
+			Summary:  "List all fixtures",
+			Response: []FixtureDTO{},
+			Errors:   []int{http.StatusInternalServerError},
+		}, h.GetAllFixtures, ""},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/changes", Tag: "fpl",
+			Summary: "Import change feed, oldest first",
+			Description: "Events from one import share `created_at`, so page with `after_id` set to the last `id` " +
+				"received; a page of exactly `limit` events may have more behind it.",
+			Params: []openapi.Param{
+				openapi.Q("since", "string", "RFC3339 timestamp, default 7 days ago (no default with after_id)"),
+				openapi.Q("after_id", "integer", "Only events after this id"),
+				openapi.Q("kind", "string", "Only this change kind, e.g. price_rise"),
+				openapi.Q("limit", "integer", "1-5000, default 500"),
+			},
+			Response: []ChangeEventDTO{},
+			Errors:   []int{http.StatusUnprocessableEntity, http.StatusInternalServerError},
+		}, h.GetChanges, ""},
+	}
+}
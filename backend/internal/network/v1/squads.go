@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"github.com/go-chi/chi"
+)
+
+const (
+	maxSquadSize = 15
+	maxStarters  = 11
+)
+
+func (h *Handler) ListSquadsHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	teams, err := h.Store.Squads.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch squads", err)
+		return
+	}
+	out := make([]SquadDTO, len(teams))
+	for i, t := range teams {
+		out[i] = toSquadDTO(t, nil)
+	}
+	response.OK(w, out)
+}
+
+func (h *Handler) GetSquadHandler(w http.ResponseWriter, r *http.Request) {
+	team, picks, ok := h.ownSquad(w, r)
+	if !ok {
+		return
+	}
+	response.OK(w, toSquadDTO(team, picks))
+}
+
+func (h *Handler) CreateSquadHandler(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFrom(r.Context())
+	var req SquadRequest
+	if !response.Decode(w, r, &req) || !h.validSquad(w, r, req) {
+		return
+	}
+
+	team := model.UserTeam{UserID: &user.ID, UserName: strings.TrimSpace(req.Name), Points: req.Points}
+	picks := toPicks(req.Picks)
+	if err := h.Store.Squads.Save(r.Context(), &team, picks); err != nil {
+		response.Internal(w, r, "Failed to save squad", err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/v1/squads/%d", team.ID))
+	response.JSON(w, http.StatusCreated, toSquadDTO(team, picks))
+}
+
+func (h *Handler) UpdateSquadHandler(w http.ResponseWriter, r *http.Request) {
+	team, _, ok := h.ownSquad(w, r)
+	if !ok {
+		return
+	}
+	var req SquadRequest
+	if !response.Decode(w, r, &req) || !h.validSquad(w, r, req) {
+		return
+	}
+
+	team.UserName = strings.TrimSpace(req.Name)
+	team.Points = req.Points
+	picks := toPicks(req.Picks)
+	if err := h.Store.Squads.Save(r.Context(), &team, picks); err != nil {
+		response.Internal(w, r, "Failed to save squad", err)
+		return
+	}
+	response.OK(w, toSquadDTO(team, picks))
+}
+
+func (h *Handler) DeleteSquadHandler(w http.ResponseWriter, r *http.Request) {
+	team, _, ok := h.ownSquad(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Store.Squads.Delete(r.Context(), team.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		response.Internal(w, r, "Failed to delete squad", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownSquad loads {id} and checks it belongs to the signed-in user. Other
+// users' squads answer 404 so ids can't be probed.
+func (h *Handler) ownSquad(w http.ResponseWriter, r *http.Request) (model.UserTeam, []model.UserTeamPlayer, bool) {
+	user, _ := auth.UserFrom(r.Context())
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.NotFound(w, r, "Squad not found")
+		return model.UserTeam{}, nil, false
+	}
+	team, picks, err := h.Store.Squads.Get(r.Context(), uint(id))
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (team.UserID == nil || *team.UserID != user.ID)) {
+		response.NotFound(w, r, "Squad not found")
+		return model.UserTeam{}, nil, false
+	}
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch squad", err)
+		return model.UserTeam{}, nil, false
+	}
+	return team, picks, true
+}
+
+func (h *Handler) validSquad(w http.ResponseWriter, r *http.Request, req SquadRequest) bool {
+	var checks response.Checks
+	name := strings.TrimSpace(req.Name)
+	checks.Require(name != "" && len(name) <= 100, "name", "must be 1-100 characters")
+	checks.Require(len(req.Picks) <= maxSquadSize, "picks", fmt.Sprintf("at most %d players", maxSquadSize))
+
+	players, err := h.Store.Players.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch players", err)
+		return false
+	}
+	known := make(map[uint]bool, len(players))
+	for _, p := range players {
+		known[p.ID] = true
+	}
+
+	seen := map[uint]bool{}
+	captains, vices, starters := 0, 0, 0
+	for i, p := range req.Picks {
+		field := fmt.Sprintf("picks[%d].player_id", i)
+		checks.Require(known[p.PlayerID], field, "unknown player")
+		checks.Require(!seen[p.PlayerID], field, "player picked twice")
+		seen[p.PlayerID] = true
+		checks.Require(!(p.IsCaptain && p.IsVice), fmt.Sprintf("picks[%d]", i), "cannot be captain and vice-captain")
+		if p.IsCaptain {
+			captains++
+		}
+		if p.IsVice {
+			vices++
+		}
+		if p.IsStarting {
+			starters++
+		}
+	}
+	checks.Require(captains <= 1, "picks", "at most one captain")
+	checks.Require(vices <= 1, "picks", "at most one vice-captain")
+	checks.Require(starters <= maxStarters, "picks", fmt.Sprintf("at most %d starters", maxStarters))
+	return !checks.Failed(w, r)
+}
+
+func toPicks(in []SquadPickDTO) []model.UserTeamPlayer {
+	out := make([]model.UserTeamPlayer, len(in))
+	for i, p := range in {
+		out[i] = model.UserTeamPlayer{PlayerID: p.PlayerID, IsCaptain: p.IsCaptain, IsVice: p.IsVice, IsStarting: p.IsStarting}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+func TestSquads(t *testing.T) {
+	api := newTestAPI(t)
+	ann := api.client(t, "ann@example.com")
+	bob := api.client(t, "bob@example.com")
+
+	var created SquadDTO
+	status := call(t, ann, http.MethodPost, api.URL+"/v1/squads", SquadRequest{Name: "Wildcard", Picks: []SquadPickDTO{
+		{PlayerID: 3, IsCaptain: true, IsStarting: true},
+		{PlayerID: 4, IsVice: true, IsStarting: true},
+		{PlayerID: 1},
+	}}, &created)
+	if status != http.StatusCreated || created.ID == 0 || len(created.Picks) != 3 {
+		t.Fatalf("create: status %d, %+v", status, created)
+	}
+	url := fmt.Sprintf("%s/v1/squads/%d", api.URL, created.ID)
+
+	var got SquadDTO
+	if status := call(t, ann, http.MethodGet, url, nil, &got); status != http.StatusOK || got.Name != "Wildcard" {
+		t.Errorf("owner GET: status %d, %+v", status, got)
+	}
+
+	// someone else's squad looks like it doesn't exist
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		var body any
+		if method == http.MethodPut {
+			body = SquadRequest{Name: "Mine now"}
+		}
+		if status := call(t, bob, method, url, body, nil); status != http.StatusNotFound {
+			t.Errorf("%s by another user: status %d, want 404", method, status)
+		}
+	}
+	var list []SquadDTO
+	if call(t, bob, http.MethodGet, api.URL+"/v1/squads", nil, &list); len(list) != 0 {
+		t.Errorf("another user lists %d squads, want 0", len(list))
+	}
+
+	if status := call(t, ann, http.MethodDelete, url, nil, nil); status != http.StatusNoContent {
+		t.Errorf("owner DELETE: status %d", status)
+	}
+}
+
+func TestValidSquad(t *testing.T) {
+	api := newTestAPI(t)
+	c := api.client(t, "ann@example.com")
+
+	tests := []struct {
+		name  string
+		req   SquadRequest
+		field string
+	}{
+		{"no name", SquadRequest{Name: " "}, "name"},
+		{"unknown player", SquadRequest{Name: "A", Picks: []SquadPickDTO{{PlayerID: 99}}}, "picks[0].player_id"},
+		{"picked twice", SquadRequest{Name: "A", Picks: []SquadPickDTO{{PlayerID: 1}, {PlayerID: 1}}}, "picks[1].player_id"},
+		{"captain and vice", SquadRequest{Name: "A", Picks: []SquadPickDTO{{PlayerID: 1, IsCaptain: true, IsVice: true}}}, "picks[0]"},
+		{"two captains", SquadRequest{Name: "A", Picks: []SquadPickDTO{{PlayerID: 1, IsCaptain: true}, {PlayerID: 2, IsCaptain: true}}}, "picks"},
+	}
+	for _, tt := range tests {
+		var env struct {
+			Error struct {
+				Code    response.Code         `json:"code"`
+				Details []response.FieldError `json:"details"`
+			} `json:"error"`
+		}
+		status := call(t, c, http.MethodPost, api.URL+"/v1/squads", tt.req, &env)
+		if status != http.StatusUnprocessableEntity || len(env.Error.Details) == 0 || env.Error.Details[0].Field != tt.field {
+			t.Errorf("%s: status %d, details %+v, want 422 on %s", tt.name, status, env.Error.Details, tt.field)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+// AskAIStreamHandler is AskAIHandler over Server-Sent Events, without tool
+// calls (the prompt carries the player pool instead):
+//
+//	event: delta  data: {"text": "..."}          as the model writes
+//	event: done   data: {"reply", "model", ...}  once, at the end
+//	event: error  data: {"code", "message", "request_id"}
+//
+// Failures before the first delta are plain JSON errors with a status code,
+// so clients only need the error event for streams that break midway.
+// Closing the connection cancels the upstream call via the request context.
+func (h *Handler) AskAIStreamHandler(w http.ResponseWriter, r *http.Request) {
+	req, prompt, ok := h.askRequest(w, r, false)
+	if !ok {
+		return
+	}
+	reqID := response.RequestIDFrom(r.Context())
+	sse := newSSEWriter(w)
+
+	c := caller(r)
+	cached, key, hit := h.Meter.Lookup(r.Context(), req)
+	if hit {
+		h.Meter.Record(r.Context(), c, "ask-ai-stream", cached.Prompt, cached.Response, true)
+		if sse.Send("delta", StreamDeltaDTO{Text: cached.Response.Text}) == nil {
+			_ = sse.Send("done", AskResponse{Reply: cached.Response.Text, Model: cached.Response.Model, Cached: true, Prompt: cached.Prompt})
+		}
+		return
+	}
+	if !h.allowAI(w, r, c) {
+		return
+	}
+
+	ctx, cancel := h.aiContext(r)
+	defer cancel()
+	resp, err := h.AI.Stream(ctx, req, func(text string) error {
+		return sse.Send("delta", StreamDeltaDTO{Text: text})
+	})
+	switch {
+	case r.Context().Err() != nil:
+		log.Printf("🔌 [%s] client left during AI stream", reqID)
+	case err != nil && !sse.started:
+		writeAIError(w, r, h.AI.Name(), err)
+	case err != nil:
+		log.Printf("❌ [%s] %s stream failed: %v", reqID, h.AI.Name(), err)
+		_, code, msg := aiError(err)
+		_ = sse.Send("error", response.ErrorBody{Code: code, Message: msg, RequestID: reqID})
+	default:
+		h.Meter.Record(r.Context(), c, "ask-ai-stream", prompt, resp, false)
+		h.Meter.Store(key, assistant.Answer{Response: resp, Prompt: prompt})
+		_ = sse.Send("done", AskResponse{Reply: resp.Text, Model: resp.Model, Prompt: prompt})
+	}
+}
+
+// sseWriter sends the SSE headers lazily with the first event, which leaves
+// room for an ordinary JSON error before that.
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	return &sseWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+func (s *sseWriter) Send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !s.started {
+		s.started = true
+		// server.write_timeout is sized for whole JSON answers, not streams
+		if err := s.rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("⚠️ can't lift write deadline for SSE: %v", err)
+		}
+		h := s.w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no") // nginx would otherwise buffer the stream
+		s.w.WriteHeader(http.StatusOK)
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAskAIStream(t *testing.T) {
+	api := newTestAPI(t)
+
+	body := strings.NewReader(`{"message": "Who should I captain?"}`)
+	resp, err := http.Post(api.URL+"/v1/ask-ai/stream", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var text strings.Builder
+	var done *AskResponse
+	var event string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			switch event {
+			case "delta":
+				var d StreamDeltaDTO
+				if err := json.Unmarshal(data, &d); err != nil {
+					t.Fatalf("bad delta %s: %v", data, err)
+				}
+				text.WriteString(d.Text)
+			case "done":
+				done = &AskResponse{}
+				if err := json.Unmarshal(data, done); err != nil {
+					t.Fatalf("bad done event %s: %v", data, err)
+				}
+			default:
+				t.Fatalf("unexpected %q event: %s", event, data)
+			}
+		}
+	}
+	if done == nil {
+		t.Fatal("stream ended without a done event")
+	}
+	if done.Reply != text.String() || done.Model != "fake" {
+		t.Errorf("done %+v doesn't match the deltas %q", done, text.String())
+	}
+}
+
+func TestAskAIStreamValidation(t *testing.T) {
+	api := newTestAPI(t)
+
+	// nothing streamed yet, so it's an ordinary JSON error
+	resp, err := http.Post(api.URL+"/v1/ask-ai/stream", "application/json", strings.NewReader(`{"message": ""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+)
+
+// SuggestTransfersHandler asks the model for transfers as JSON and returns
+// them only once every player, price and club limit checks out.
+func (h *Handler) SuggestTransfersHandler(w http.ResponseWriter, r *http.Request) {
+	h.extendWriteDeadline(w)
+	var req TransferSuggestRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	free := 1
+	if req.FreeTransfers != nil {
+		free = *req.FreeTransfers
+	}
+	var checks response.Checks
+	checks.Require(len(req.Squad.Starters)+len(req.Squad.Bench) > 0, "squad", "is required")
+	checkAskSquad(&checks, &req.Squad)
+	checks.Require(free >= 0 && free <= assistant.MaxTransfers, "free_transfers", fmt.Sprintf("must be 0-%d", assistant.MaxTransfers))
+	checks.Require(len(req.Notes) <= 500, "notes", "at most 500 characters")
+	notes := h.cleanInput(&checks, "notes", req.Notes, false)
+	if checks.Failed(w, r) {
+		return
+	}
+
+	c := caller(r)
+	if !h.allowAI(w, r, c) {
+		return
+	}
+
+	ctx, cancel := h.aiContext(r)
+	defer cancel()
+	s, err := h.Advisor.Suggest(ctx, *req.Squad.toSquad(), free, notes)
+	var storeErr *assistant.StorageError
+	var invalid *assistant.InvalidSuggestionError
+	switch {
+	case errors.As(err, &storeErr):
+		response.Internal(w, r, "Failed to load FPL data for the assistant", err)
+		return
+	case errors.As(err, &invalid):
+		// the tokens were spent all the same
+		h.Meter.Record(r.Context(), c, "transfers", invalid.Prompt, ai.Response{Usage: invalid.Usage}, false)
+		response.ErrorDetails(w, r, http.StatusBadGateway, response.CodeUpstream,
+			"The AI assistant couldn't come up with a valid suggestion; try again", invalid.Problems)
+		return
+	case err != nil:
+		writeAIError(w, r, h.AI.Name(), err)
+		return
+	}
+
+	h.Meter.Record(r.Context(), c, "transfers", s.Prompt, ai.Response{Model: s.Model, Usage: s.Usage}, false)
+
+	teams, err := h.Store.Teams.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch teams", err)
+		return
+	}
+	response.OK(w, toTransferSuggestionDTO(s, teamShortNames(teams)))
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSuggestTransfers(t *testing.T) {
+	api := newTestAPI(t)
+	url := api.URL + "/v1/transfers/suggest"
+
+	// the fake model suggests no moves, which is always valid
+	var s TransferSuggestionDTO
+	status := call(t, http.DefaultClient, http.MethodPost, url, TransferSuggestRequest{
+		Squad: AskSquad{Starters: []uint{1, 2, 3}, Bench: []uint{5}, CaptainID: 3, BudgetLeft: 1.5},
+	}, &s)
+	if status != http.StatusOK {
+		t.Fatalf("suggest: status %d", status)
+	}
+	if len(s.Transfers) != 0 || s.Attempts != 1 || s.BudgetLeft != 1.5 || s.Reasoning == "" {
+		t.Errorf("suggest: unexpected suggestion %+v", s)
+	}
+
+	free := 9
+	for name, req := range map[string]TransferSuggestRequest{
+		"empty squad":         {},
+		"too many free moves": {Squad: AskSquad{Starters: []uint{1}}, FreeTransfers: &free},
+		"vice not in squad":   {Squad: AskSquad{Starters: []uint{1}, ViceID: 4}},
+	} {
+		if status := call(t, http.DefaultClient, http.MethodPost, url, req, nil); status != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status %d, want 422", name, status)
+		}
+	}
+}
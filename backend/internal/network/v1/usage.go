@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"github.com/go-chi/chi"
+)
+
+// caller is who an AI request counts against: the signed-in user, else the
+// client IP.
+func caller(r *http.Request) assistant.Caller {
+	c := assistant.Caller{Client: auth.ClientIP(r)}
+	if u, ok := auth.UserFrom(r.Context()); ok {
+		c.UserID = u.ID
+	}
+	return c
+}
+
+// allowAI enforces the daily quota. It has answered the client when false.
+func (h *Handler) allowAI(w http.ResponseWriter, r *http.Request, c assistant.Caller) bool {
+	err := h.Meter.Allow(r.Context(), c)
+	var quota *assistant.QuotaError
+	switch {
+	case errors.As(err, &quota):
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quota.ResetAt).Seconds())+1))
+		response.ErrorDetails(w, r, http.StatusTooManyRequests, response.CodeQuotaExceeded,
+			"You've used today's AI questions; try again tomorrow",
+			map[string]any{"limit": quota.Limit, "reset_at": quota.ResetAt})
+		return false
+	case err != nil:
+		response.Internal(w, r, "Failed to check AI quota", err)
+		return false
+	}
+	return true
+}
+
+// AIUsageHandler reports AI requests and tokens.
+// Query: since (RFC3339, default midnight UTC 6 days ago), until (RFC3339, default now).
+func (h *Handler) AIUsageHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	since, until := now.Truncate(24*time.Hour).AddDate(0, 0, -6), now
+
+	var checks response.Checks
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		checks.Require(err == nil, "since", "expected an RFC3339 timestamp")
+		since = t
+	}
+	if v := q.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		checks.Require(err == nil, "until", "expected an RFC3339 timestamp")
+		until = t
+	}
+	checks.Require(since.Before(until), "until", "must be after since")
+	if checks.Failed(w, r) {
+		return
+	}
+
+	rows, err := h.Store.AIUsage.List(r.Context(), since, until)
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch AI usage", err)
+		return
+	}
+	report := assistant.Summarize(rows)
+	response.OK(w, AIUsageReportDTO{
+		Since:      since,
+		Until:      until,
+		DailyQuota: h.Meter.DailyQuota(),
+		Total:      AIUsageTotalsDTO(report.Total),
+		ByDay:      toAIUsageTotalsDTOs(report.ByDay),
+		ByEndpoint: toAIUsageTotalsDTOs(report.ByEndpoint),
+		ByModel:    toAIUsageTotalsDTOs(report.ByModel),
+		ByPrompt:   toAIUsageTotalsDTOs(report.ByPrompt),
+		ByCaller:   toAIUsageTotalsDTOs(report.ByCaller),
+	})
+}
+
+func (h *Handler) ListPromptsHandler(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.Assistant.Templates())
+}
+
+// RenderPromptHandler renders one template against live data so edits can be
+// checked without asking the model anything.
+func (h *Handler) RenderPromptHandler(w http.ResponseWriter, r *http.Request) {
+	var req PromptRenderRequest
+	if r.ContentLength != 0 && !response.Decode(w, r, &req) {
+		return
+	}
+	free := 1
+	if req.FreeTransfers != nil {
+		free = *req.FreeTransfers
+	}
+	var checks response.Checks
+	if req.Squad != nil {
+		checkAskSquad(&checks, req.Squad)
+	}
+	checks.Require(free >= 0 && free <= assistant.MaxTransfers, "free_transfers", fmt.Sprintf("must be 0-%d", assistant.MaxTransfers))
+	if checks.Failed(w, r) {
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	known := false
+	for _, t := range h.Assistant.Templates() {
+		known = known || t.Name == name
+	}
+	if !known {
+		response.NotFound(w, r, "No such prompt template")
+		return
+	}
+	p, err := h.Assistant.Render(r.Context(), name, req.Squad.toSquad(), free, req.Tools, time.Now())
+	if err != nil {
+		response.Internal(w, r, "Failed to render prompt template", err)
+		return
+	}
+	response.OK(w, PromptRenderDTO{Name: name, Version: p.Version, Text: p.Text})
+}
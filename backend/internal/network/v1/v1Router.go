@@ -2,92 +2,383 @@ package v1
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/fplimporter"
-	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
 	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/scheduler"
 	"github.com/go-chi/chi"
 )
 
-type askReq struct {
-	Message string `json:"message"`
+// Deps are the services behind the v1 API. main.go wires the real ones;
+// handler tests can pass memory.NewStore() and leave the rest nil.
+type Deps struct {
+	Store     *repository.Store
+	Importer  *fplimporter.Importer
+	Jobs      *jobs.Manager
+	Scheduler *scheduler.Scheduler
+	Auth      *auth.KeyStore
+	Sessions  *auth.Sessions
+	AI        ai.Provider
+	Assistant *assistant.Builder
+	Chat      *assistant.Chat
+	Tools     *assistant.Tools
+	Advisor   *assistant.Advisor
+	Meter     *assistant.Meter
+	Guard     *assistant.InputGuard
+	Reporter  *assistant.Reporter
+	// Cancelled when the server starts shutting down; AI calls stop then
+	// rather than holding up the drain. Nil means never.
+	Shutdown context.Context
+	// Write deadline for AI answers, which can outlast server.write_timeout
+	// by a few tool rounds. 0 leaves the server's deadline alone.
+	AIWriteTimeout time.Duration
 }
 
-func NewV1Router() chi.Router {
+type Handler struct {
+	Deps
+}
+
+func NewV1Router(d Deps) chi.Router {
+	h := &Handler{Deps: d}
 	r := chi.NewRouter()
+	r.Use(response.RequestID)
+	r.Use(response.Recover)
 
-	r.Post("/admin/import-fpl", ImportFPLHandler)
-	r.Post("/ask-ai", AskAIHandler)
+	routes := h.routes()
+	for _, rt := range routes {
+		var handler http.Handler = rt.handler
+		switch rt.role {
+		case "":
+		case auth.RoleUser:
+			handler = h.Sessions.RequireUser(handler)
+		case auth.RoleGuest:
+			if h.Sessions != nil {
+				handler = h.Sessions.OptionalUser(handler)
+			}
+		default:
+			handler = h.Auth.Require(rt.role)(handler)
+		}
+		r.Method(rt.Method, rt.Path, handler)
+	}
+	r.Get("/openapi.json", serveSpec(buildSpec(routes)))
+	docs, redocJS := docsHandlers()
+	r.Get("/docs", docs)
+	r.Get("/docs/redoc.js", redocJS)
 
-	r.Get("/players", GetAllPlayers)
-	r.Get("/teams", GetAllTeams)
-	r.Get("/fixtures", GetAllFixtures)
+	// Keep API 404s JSON instead of inheriting the SPA fallback
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.NotFound(w, r, "No such endpoint")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, r, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
+	})
 
 	return r
 }
 
-func ImportFPLHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+func (h *Handler) ImportFPLHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.Importer.SubmitImport(h.Jobs, "api")
+	if err != nil && !errors.Is(err, jobs.ErrAlreadyRunning) {
+		response.Internal(w, r, "Failed to start FPL import", err)
+		return
+	}
 
-	if err := fplimporter.ImportFPLData(ctx); err != nil {
-		log.Printf("❌ Import failed: %v", err)
-		http.Error(w, "Failed to import FPL data", http.StatusInternalServerError)
+	id := job.Snapshot().ID
+	w.Header().Set("Location", "/v1/admin/jobs/"+id)
+	if err != nil {
+		response.ErrorDetails(w, r, http.StatusConflict, response.CodeConflict,
+			"An FPL import is already running", map[string]string{"job_id": id})
 		return
 	}
+	response.JSON(w, http.StatusAccepted, JobAcceptedDTO{JobID: id})
+}
+
+func (h *Handler) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.Jobs.List())
+}
+
+func (h *Handler) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.Jobs.Get(chi.URLParam(r, "id"))
+	if !ok {
+		response.NotFound(w, r, "Job not found")
+		return
+	}
+	response.OK(w, job.Snapshot())
+}
+
+func (h *Handler) CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	switch err := h.Jobs.Cancel(chi.URLParam(r, "id")); {
+	case errors.Is(err, jobs.ErrNotFound):
+		response.NotFound(w, r, "Job not found")
+	case errors.Is(err, jobs.ErrFinished):
+		response.Conflict(w, r, "Job already finished")
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (h *Handler) SyncStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var status scheduler.Status
+	if h.Scheduler != nil {
+		status = h.Scheduler.Status()
+	}
+	response.OK(w, status)
+}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("✅ FPL data imported successfully."))
+func (h *Handler) GetAllPlayers(w http.ResponseWriter, r *http.Request) {
+	players, err := h.Store.Players.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch players", err)
+		return
+	}
+	teams, err := h.Store.Teams.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch teams", err)
+		return
+	}
+	response.OK(w, toPlayerDTOs(players, teamShortNames(teams)))
 }
 
-func GetAllPlayers(w http.ResponseWriter, r *http.Request) {
-	var players []model.Player
-	if err := repository.DB.Find(&players).Error; err != nil {
-		http.Error(w, "Failed to fetch players", http.StatusInternalServerError)
+func (h *Handler) GetAllTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := h.Store.Teams.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch teams", err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(players)
+	response.OK(w, toTeamDTOs(teams))
 }
 
-func GetAllTeams(w http.ResponseWriter, r *http.Request) {
-	var teams []model.Team
-	if err := repository.DB.Find(&teams).Error; err != nil {
-		http.Error(w, "Failed to fetch players", http.StatusInternalServerError)
+func (h *Handler) GetAllFixtures(w http.ResponseWriter, r *http.Request) {
+	fixtures, err := h.Store.Fixtures.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch fixtures", err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(teams)
+	teams, err := h.Store.Teams.List(r.Context())
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch teams", err)
+		return
+	}
+	response.OK(w, toFixtureDTOs(fixtures, teamShortNames(teams)))
 }
-func GetAllFixtures(w http.ResponseWriter, r *http.Request) {
-	var fixtures []model.Fixture
-	if err := repository.DB.Find(&fixtures).Error; err != nil {
-		http.Error(w, "Failed to fetch players", http.StatusInternalServerError)
+
+// GetChanges serves the import change feed, oldest first.
+// Query: since (RFC3339, default 7 days ago unless after_id is set), after_id
+// (page on from the last id seen), kind (optional), limit (default 500).
+func (h *Handler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	f := repository.ChangeFilter{
+		Since: time.Now().Add(-7 * 24 * time.Hour),
+		Kind:  q.Get("kind"),
+		Limit: 500,
+	}
+	var checks response.Checks
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		checks.Require(err == nil, "since", "expected an RFC3339 timestamp")
+		f.Since = t
+	}
+	if v := q.Get("after_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		checks.Require(err == nil, "after_id", "expected an event id")
+		f.AfterID = uint(id)
+		if q.Get("since") == "" {
+			f.Since = time.Time{} // the cursor alone says where to go on
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		checks.Require(err == nil && n >= 1 && n <= 5000, "limit", "expected an integer between 1 and 5000")
+		f.Limit = n
+	}
+	if checks.Failed(w, r) {
+		return
+	}
+
+	events, err := h.Store.Changes.List(r.Context(), f)
+	if err != nil {
+		response.Internal(w, r, "Failed to fetch changes", err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(fixtures)
+	response.OK(w, toChangeEventDTOs(events))
 }
-func AskAIHandler(w http.ResponseWriter, r *http.Request) {
-	var req askReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Message == "" {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+
+func (h *Handler) AskAIHandler(w http.ResponseWriter, r *http.Request) {
+	h.extendWriteDeadline(w)
+	withTools := h.Tools.Enabled()
+	req, prompt, ok := h.askRequest(w, r, withTools)
+	if !ok {
+		return
+	}
+
+	c := caller(r)
+	cached, key, hit := h.Meter.Lookup(r.Context(), req)
+	if hit {
+		h.Meter.Record(r.Context(), c, "ask-ai", cached.Prompt, cached.Response, true)
+		response.OK(w, AskResponse{Reply: cached.Response.Text, Model: cached.Response.Model,
+			Tools: toToolTraceDTOs(cached.Tools), Cached: true, Prompt: cached.Prompt})
+		return
+	}
+	if !h.allowAI(w, r, c) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 25*time.Second)
+	ctx, cancel := h.aiContext(r)
 	defer cancel()
+	var resp ai.Response
+	var trace []assistant.ToolTrace
+	var err error
+	if withTools {
+		resp, trace, err = h.Tools.Run(ctx, h.AI, req)
+	} else {
+		resp, err = h.AI.Generate(ctx, req)
+	}
+	var storeErr *assistant.StorageError
+	if errors.As(err, &storeErr) {
+		response.Internal(w, r, "Failed to load FPL data for the assistant", err)
+		return
+	}
+	if err != nil {
+		writeAIError(w, r, h.AI.Name(), err)
+		return
+	}
+
+	h.Meter.Record(r.Context(), c, "ask-ai", prompt, resp, false)
+	h.Meter.Store(key, assistant.Answer{Response: resp, Tools: trace, Prompt: prompt})
+	response.OK(w, AskResponse{Reply: resp.Text, Model: resp.Model, Tools: toToolTraceDTOs(trace), Prompt: prompt})
+}
+
+// askRequest decodes and validates an AskRequest and grounds it in the FPL
+// data; a model with tools gets the shorter prompt. Besides the request it
+// returns the prompt template versions. It has answered the client when ok
+// is false.
+func (h *Handler) askRequest(w http.ResponseWriter, r *http.Request, withTools bool) (ai.Request, string, bool) {
+	var req AskRequest
+	if !response.Decode(w, r, &req) {
+		return ai.Request{}, "", false
+	}
+	var checks response.Checks
+	message := h.cleanInput(&checks, "message", req.Message, true)
+	if req.Squad != nil {
+		checkAskSquad(&checks, req.Squad)
+	}
+	if checks.Failed(w, r) {
+		return ai.Request{}, "", false
+	}
 
-	reply, err := ai.CallGemini(ctx, req.Message)
+	var system assistant.Prompt
+	if h.Assistant != nil {
+		var err error
+		build := h.Assistant.SystemPrompt
+		if withTools {
+			build = h.Assistant.ToolPrompt
+		}
+		system, err = build(r.Context(), req.Squad.toSquad(), time.Now())
+		if err != nil {
+			response.Internal(w, r, "Failed to load FPL data for the assistant", err)
+			return ai.Request{}, "", false
+		}
+	}
+	return ai.Request{
+		System:   system.Text,
+		Messages: []ai.Message{{Role: ai.RoleUser, Content: message}},
+	}, system.Version, true
+}
+
+// cleanInput runs user text through the guard, recording a failed check for
+// field when it's too long, or empty and required.
+func (h *Handler) cleanInput(checks *response.Checks, field, text string, required bool) string {
+	clean, err := h.Guard.Clean(text)
 	if err != nil {
-		http.Error(w, "Gemini API failed: "+err.Error(), http.StatusInternalServerError)
+		checks.Require(false, field, err.Error())
+		return ""
+	}
+	checks.Require(!required || clean != "", field, "is required")
+	return clean
+}
+
+// checkAskSquad only checks the shape; unknown ids are flagged in the prompt
+// rather than rejected, since the client may hold a stale player list.
+func checkAskSquad(checks *response.Checks, s *AskSquad) {
+	checks.Require(len(s.Starters) <= 11, "squad.starters", "at most 11 players")
+	checks.Require(len(s.Starters)+len(s.Bench) <= 15, "squad", "at most 15 players")
+	seen := map[uint]bool{}
+	dup := false
+	for _, id := range append(append([]uint{}, s.Starters...), s.Bench...) {
+		dup = dup || seen[id]
+		seen[id] = true
+	}
+	checks.Require(!dup, "squad", "players must be unique")
+	checks.Require(s.CaptainID == 0 || seen[s.CaptainID], "squad.captain_id", "must be in the squad")
+	checks.Require(s.ViceID == 0 || seen[s.ViceID], "squad.vice_id", "must be in the squad")
+	checks.Require(s.CaptainID == 0 || s.CaptainID != s.ViceID, "squad.vice_id", "must differ from the captain")
+	checks.Require(s.BudgetLeft >= 0 && s.BudgetLeft <= 100, "squad.budget_left", "must be between 0 and 100")
+}
+
+// aiContext is r's context, also cancelled once the server starts shutting
+// down. Other requests drain normally; AI calls can take long enough to
+// outlast the shutdown timeout.
+func (h *Handler) aiContext(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	if h.Shutdown == nil {
+		return ctx, cancel
+	}
+	stop := context.AfterFunc(h.Shutdown, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// writeAIError maps provider failures to user-facing errors. Details stay in
+// the log; upstream bodies can echo keys or prompts.
+func writeAIError(w http.ResponseWriter, r *http.Request, provider string, err error) {
+	log.Printf("❌ [%s] %s call failed: %v", response.RequestIDFrom(r.Context()), provider, err)
+	status, code, msg := aiError(err)
+	response.Error(w, r, status, code, msg)
+}
+
+// extendWriteDeadline gives an AI answer AIWriteTimeout from now to be
+// written, however short server.write_timeout is.
+func (h *Handler) extendWriteDeadline(w http.ResponseWriter) {
+	if h.AIWriteTimeout <= 0 {
 		return
 	}
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.AIWriteTimeout)); err != nil {
+		log.Printf("⚠️ can't extend write deadline for AI reply: %v", err)
+	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"reply": reply})
+func aiError(err error) (int, response.Code, string) {
+	var blocked *ai.BlockedError
+	var apiErr *ai.APIError
+	switch {
+	case errors.Is(err, ai.ErrNotConfigured):
+		return http.StatusServiceUnavailable, response.CodeUnavailable, "The AI assistant is not configured on this server"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, response.CodeTimeout, "The AI assistant took too long to answer"
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, response.CodeUnavailable, "The server is restarting; try again shortly"
+	case errors.As(err, &blocked):
+		return http.StatusUnprocessableEntity, response.CodeContentBlocked, "The AI assistant declined to answer that; try rephrasing your question"
+	case errors.Is(err, ai.ErrTruncated):
+		return http.StatusBadGateway, response.CodeUpstream, "The AI assistant's answer was too long; try a narrower question"
+	case errors.As(err, &apiErr) && apiErr.Status == http.StatusTooManyRequests:
+		return http.StatusServiceUnavailable, response.CodeUnavailable, "The AI assistant is busy; try again in a minute"
+	case errors.As(err, &apiErr) && (apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden):
+		return http.StatusServiceUnavailable, response.CodeUnavailable, "The AI assistant is not configured correctly on this server"
+	default:
+		return http.StatusBadGateway, response.CodeUpstream, "The AI assistant is unavailable right now"
+	}
 }
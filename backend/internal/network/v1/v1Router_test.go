@@ -0,0 +1,331 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/ai"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/assistant"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/auth"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/network/response"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/prompts"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository/memory"
+	"github.com/go-chi/chi"
+)
+
+const testAdminKey = "test-admin-key-0123456789"
+
+// testAPI is the v1 router over a memory store and the fake AI, seeded with
+// two clubs, a handful of players and one upcoming fixture.
+type testAPI struct {
+	*httptest.Server
+	store *repository.Store
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	return newTestAPIWith(t, ai.Fake{}, 0, 0)
+}
+
+// newTestAPIWith runs the API on provider behind a server with the given
+// write timeout (0 = none), AI routes extending it to aiWriteTimeout.
+func newTestAPIWith(t *testing.T, provider ai.Provider, writeTimeout, aiWriteTimeout time.Duration) *testAPI {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	store := memory.NewStore()
+	seed(t, store)
+	templates, err := prompts.Load("")
+	if err != nil {
+		t.Fatalf("loading prompts: %v", err)
+	}
+	builder := assistant.NewBuilder(store, templates)
+	meter := assistant.NewMeter(store, provider.Name(), 0, time.Minute, 100)
+
+	r := chi.NewRouter()
+	r.Mount("/v1", NewV1Router(Deps{
+		Store:     store,
+		Jobs:      jobs.NewManager(ctx),
+		Auth:      auth.NewKeyStore([]config.APIKeyConfig{{Name: "test", Key: testAdminKey, Roles: []string{"admin"}}}),
+		Sessions:  auth.NewSessions(store, &config.AuthConfig{SessionTTL: time.Hour}, false),
+		AI:        provider,
+		Assistant: builder,
+		Chat:      assistant.NewChat(store, provider, builder, 3000),
+		Tools:     assistant.NewTools(store, 2, 0),
+		Advisor:   assistant.NewAdvisor(store, provider, builder, 0),
+		Meter:     meter,
+		Guard:     assistant.NewInputGuard(2000),
+		Reporter:  assistant.NewReporter(store, provider, builder, meter),
+		Shutdown:  ctx,
+
+		AIWriteTimeout: aiWriteTimeout,
+	}))
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.WriteTimeout = writeTimeout
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return &testAPI{Server: srv, store: store}
+}
+
+func seed(t *testing.T, store *repository.Store) {
+	t.Helper()
+	ctx := context.Background()
+	gw := 10
+	kickoff := time.Now().Add(48 * time.Hour)
+	err := errors.Join(
+		store.Teams.Upsert(ctx, []model.Team{{ID: 1, Name: "Arsenal", ShortName: "ARS"}, {ID: 2, Name: "Liverpool", ShortName: "LIV"}}),
+		store.Players.Upsert(ctx, []model.Player{
+			{ID: 1, WebName: "Raya", TeamID: 1, Position: "GK", CurrentPrice: 5.5, Form: "4.0"},
+			{ID: 2, WebName: "Saliba", TeamID: 1, Position: "DEF", CurrentPrice: 6.0, Form: "5.0"},
+			{ID: 3, WebName: "Saka", TeamID: 1, Position: "MID", CurrentPrice: 10.0, Form: "7.5"},
+			{ID: 4, WebName: "Salah", TeamID: 2, Position: "MID", CurrentPrice: 13.0, Form: "9.0"},
+			{ID: 5, WebName: "Gakpo", TeamID: 2, Position: "MID", CurrentPrice: 7.5, Form: "6.0"},
+			{ID: 6, WebName: "Havertz", TeamID: 1, Position: "FWD", CurrentPrice: 8.0, Form: "3.0"},
+		}),
+		store.Fixtures.Upsert(ctx, []model.Fixture{{ID: 1, Event: &gw, KickoffTime: &kickoff, TeamHID: 1, TeamAID: 2, TeamHDifficulty: 4, TeamADifficulty: 4}}),
+	)
+	if err != nil {
+		t.Fatalf("seeding store: %v", err)
+	}
+}
+
+// client returns a signed-in client for a fresh account.
+func (api *testAPI) client(t *testing.T, email string) *http.Client {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	c := &http.Client{Jar: jar}
+	status := call(t, c, http.MethodPost, api.URL+"/v1/auth/signup",
+		SignupRequest{Email: email, Password: "password123", DisplayName: "Tester"}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("signup %s: status %d", email, status)
+	}
+	return c
+}
+
+// call sends body as JSON and decodes the reply into out when it's set.
+func call(t *testing.T, c *http.Client, method, url string, body, out any) int {
+	t.Helper()
+	var rd io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		rd = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, url, rd)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decoding %d reply: %v", method, url, resp.StatusCode, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestFPLData(t *testing.T) {
+	api := newTestAPI(t)
+
+	var players []PlayerDTO
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/players", nil, &players); status != http.StatusOK {
+		t.Fatalf("GET /players: status %d", status)
+	}
+	if len(players) != 6 || players[3].WebName != "Salah" || players[3].TeamShortName != "LIV" {
+		t.Errorf("GET /players: unexpected players %+v", players)
+	}
+
+	var fixtures []FixtureDTO
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/fixtures", nil, &fixtures); status != http.StatusOK || len(fixtures) != 1 {
+		t.Errorf("GET /fixtures: status %d, %d fixtures", status, len(fixtures))
+	}
+
+	var env response.Envelope
+	status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/changes?since=yesterday", nil, &env)
+	if status != http.StatusUnprocessableEntity || env.Error.Code != response.CodeValidation {
+		t.Errorf("GET /changes with a bad since: status %d, code %q", status, env.Error.Code)
+	}
+}
+
+func TestAskAI(t *testing.T) {
+	api := newTestAPI(t)
+	url := api.URL + "/v1/ask-ai"
+
+	var first AskResponse
+	if status := call(t, http.DefaultClient, http.MethodPost, url, AskRequest{Message: "Who should I captain?"}, &first); status != http.StatusOK {
+		t.Fatalf("ask: status %d", status)
+	}
+	if first.Model != "fake" || first.Reply == "" || first.Cached || first.Prompt == "" {
+		t.Errorf("ask: unexpected reply %+v", first)
+	}
+
+	var second AskResponse
+	call(t, http.DefaultClient, http.MethodPost, url, AskRequest{Message: "Who should I captain?"}, &second)
+	if !second.Cached || second.Reply != first.Reply {
+		t.Errorf("repeated question: want the cached reply, got %+v", second)
+	}
+
+	var env response.Envelope
+	status := call(t, http.DefaultClient, http.MethodPost, url, AskRequest{Message: "  "}, &env)
+	if status != http.StatusUnprocessableEntity || env.Error.Code != response.CodeValidation {
+		t.Errorf("blank question: status %d, code %q", status, env.Error.Code)
+	}
+	status = call(t, http.DefaultClient, http.MethodPost, url,
+		AskRequest{Message: "Captain?", Squad: &AskSquad{Starters: []uint{1, 1}}}, &env)
+	if status != http.StatusUnprocessableEntity {
+		t.Errorf("duplicate squad players: status %d", status)
+	}
+}
+
+func TestAdminRequiresKey(t *testing.T) {
+	api := newTestAPI(t)
+
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/admin/ai-usage", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("no key: status %d, want 401", status)
+	}
+
+	call(t, http.DefaultClient, http.MethodPost, api.URL+"/v1/ask-ai", AskRequest{Message: "Hello"}, nil)
+	req, _ := http.NewRequest(http.MethodGet, api.URL+"/v1/admin/ai-usage", nil)
+	req.Header.Set(auth.APIKeyHeader, testAdminKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var usage AIUsageReportDTO
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("with key: status %d, %v", resp.StatusCode, err)
+	}
+	if usage.Total.Requests != 1 || usage.Total.PromptTokens == 0 {
+		t.Errorf("usage after one question: %+v", usage.Total)
+	}
+}
+
+func TestAIError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   response.Code
+	}{
+		{ai.ErrNotConfigured, http.StatusServiceUnavailable, response.CodeUnavailable},
+		{fmt.Errorf("gemini: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, response.CodeTimeout},
+		{context.Canceled, http.StatusServiceUnavailable, response.CodeUnavailable},
+		{&ai.BlockedError{Provider: "gemini", Reason: "SAFETY"}, http.StatusUnprocessableEntity, response.CodeContentBlocked},
+		{fmt.Errorf("openai: %w", ai.ErrTruncated), http.StatusBadGateway, response.CodeUpstream},
+		{&ai.APIError{Provider: "openai", Status: http.StatusTooManyRequests}, http.StatusServiceUnavailable, response.CodeUnavailable},
+		{&ai.APIError{Provider: "openai", Status: http.StatusUnauthorized}, http.StatusServiceUnavailable, response.CodeUnavailable},
+		{&ai.APIError{Provider: "openai", Status: http.StatusInternalServerError}, http.StatusBadGateway, response.CodeUpstream},
+		{errors.New("connection reset"), http.StatusBadGateway, response.CodeUpstream},
+	}
+	for _, tt := range tests {
+		status, code, msg := aiError(tt.err)
+		if status != tt.status || code != tt.code || msg == "" {
+			t.Errorf("aiError(%v) = %d %q %q, want %d %q", tt.err, status, code, msg, tt.status, tt.code)
+		}
+	}
+}
+
+func TestOpenAPIGuestRoutes(t *testing.T) {
+	api := newTestAPI(t)
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Security  []map[string][]string `json:"security"`
+			Responses map[string]any        `json:"responses"`
+		} `json:"paths"`
+	}
+	if status := call(t, http.DefaultClient, http.MethodGet, api.URL+"/v1/openapi.json", nil, &spec); status != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", status)
+	}
+	op := spec.Paths["/ask-ai"]["post"]
+	if len(op.Security) != 2 || len(op.Security[1]) != 0 {
+		t.Errorf("/ask-ai should accept a session or nothing, got %v", op.Security)
+	}
+	if _, ok := op.Responses["401"]; ok {
+		t.Error("/ask-ai documents a 401 but works signed out")
+	}
+}
+
+func TestChangesAfterID(t *testing.T) {
+	api := newTestAPI(t)
+	at := time.Now()
+	var batch []model.ChangeEvent
+	for i := 1; i <= 5; i++ {
+		batch = append(batch, model.ChangeEvent{Kind: "price_change", EntityType: "player", EntityID: uint(i), Summary: "price moved", CreatedAt: at})
+	}
+	if err := api.store.Changes.Append(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []uint
+	url := api.URL + "/v1/changes?limit=2"
+	for range 5 {
+		var page []ChangeEventDTO
+		if status := call(t, http.DefaultClient, http.MethodGet, url, nil, &page); status != http.StatusOK {
+			t.Fatalf("GET %s: status %d", url, status)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, e := range page {
+			seen = append(seen, e.EntityID)
+		}
+		url = fmt.Sprintf("%s/v1/changes?limit=2&after_id=%d", api.URL, page[len(page)-1].ID)
+	}
+	if fmt.Sprint(seen) != "[1 2 3 4 5]" {
+		t.Errorf("paging with after_id saw %v, want all five events once", seen)
+	}
+}
+
+// slowAI is the fake provider taking its time over every answer.
+type slowAI struct {
+	ai.Fake
+	delay time.Duration
+}
+
+func (s slowAI) Generate(ctx context.Context, req ai.Request) (ai.Response, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return ai.Response{}, ctx.Err()
+	}
+	return s.Fake.Generate(ctx, req)
+}
+
+// TestAIOutlastsWriteTimeout checks that an AI answer slower than
+// server.write_timeout still arrives once the route extends its deadline.
+func TestAIOutlastsWriteTimeout(t *testing.T) {
+	provider := slowAI{delay: 300 * time.Millisecond}
+
+	api := newTestAPIWith(t, provider, 100*time.Millisecond, 0)
+	_, err := http.Post(api.URL+"/v1/ask-ai", "application/json", strings.NewReader(`{"message":"Captain?"}`))
+	if err == nil {
+		t.Fatal("without the extension the server should cut the reply off")
+	}
+
+	api = newTestAPIWith(t, provider, 100*time.Millisecond, 5*time.Second)
+	var reply AskResponse
+	if status := call(t, http.DefaultClient, http.MethodPost, api.URL+"/v1/ask-ai", AskRequest{Message: "Captain?"}, &reply); status != http.StatusOK {
+		t.Fatalf("ask: status %d", status)
+	}
+	if reply.Reply == "" {
+		t.Errorf("ask: empty reply %+v", reply)
+	}
+}
@@ -0,0 +1,179 @@
+// Package prompts loads the AI prompt templates (assets/prompts/*.tmpl).
+// Each file starts with a version tag, {{/* version: N */}}, which is
+// recorded with every answer it helped produce.
+package prompts
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/assets"
+)
+
+var versionTag = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*([\w.-]+)\s*\*/\s*-?\}\}`)
+
+// Info describes one template file.
+type Info struct {
+	Name    string `json:"name"` // file name without .tmpl
+	Version string `json:"version"`
+}
+
+// Set is a parsed set of templates. Templates can use each other's
+// {{define}}s. Loaded from a directory, it re-parses when a file changes.
+type Set struct {
+	dir string // "" = embedded
+
+	mu       sync.RWMutex
+	tmpl     *template.Template
+	versions map[string]string
+	stamp    string // file names and mod times the set was parsed from
+	bad      string // stamp of files that failed to parse, so it's only reported once
+}
+
+// Load parses the embedded templates, or those in dir when it's set (dev
+// mode: edits are picked up on the next render).
+func Load(dir string) (*Set, error) {
+	s := &Set{}
+	if dir != "" {
+		resolved, ok := findDir(dir)
+		if !ok {
+			return nil, fmt.Errorf("❌ prompts_dir %s not found", dir)
+		}
+		s.dir = resolved
+		log.Printf("📂 Prompt templates from %s (reloaded on change)", resolved)
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Render executes template name and returns the text with its version tag,
+// e.g. "squad@2".
+func (s *Set) Render(name string, data any) (string, string, error) {
+	if s.dir != "" {
+		if err := s.reload(); err != nil {
+			// keep serving the last good set while the file is being edited
+			log.Printf("⚠️ prompt templates not reloaded: %v", err)
+		}
+	}
+
+	s.mu.RLock()
+	tmpl, version := s.tmpl, s.versions[name]
+	s.mu.RUnlock()
+	if version == "" {
+		return "", "", fmt.Errorf("❌ no prompt template %q", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name+".tmpl", data); err != nil {
+		return "", "", fmt.Errorf("❌ prompt template %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), name + "@" + version, nil
+}
+
+// List returns the templates by name.
+func (s *Set) List() []Info {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Info, 0, len(s.versions))
+	for name, v := range s.versions {
+		out = append(out, Info{Name: name, Version: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Set) source() fs.FS {
+	if s.dir != "" {
+		return os.DirFS(s.dir)
+	}
+	sub, _ := fs.Sub(assets.Prompts, "prompts")
+	return sub
+}
+
+// reload parses the templates unless nothing changed since the last time.
+func (s *Set) reload() error {
+	fsys := s.source()
+	files, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("❌ no *.tmpl prompt templates found")
+	}
+	var stamp strings.Builder
+	for _, f := range files {
+		var mod time.Time
+		if info, err := fs.Stat(fsys, f); err == nil {
+			mod = info.ModTime()
+		}
+		fmt.Fprintf(&stamp, "%s@%d;", f, mod.UnixNano())
+	}
+	s.mu.RLock()
+	same := (s.tmpl != nil && s.stamp == stamp.String()) || s.bad == stamp.String()
+	s.mu.RUnlock()
+	if same {
+		return nil
+	}
+	if err := s.parse(fsys, files, stamp.String()); err != nil {
+		s.mu.Lock()
+		s.bad = stamp.String()
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Set) parse(fsys fs.FS, files []string, stamp string) error {
+	versions := map[string]string{}
+	for _, f := range files {
+		raw, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return err
+		}
+		m := versionTag.FindSubmatch(raw)
+		if m == nil {
+			return fmt.Errorf("❌ prompt template %s must start with {{/* version: N */}}", f)
+		}
+		versions[strings.TrimSuffix(f, ".tmpl")] = string(m[1])
+	}
+	tmpl, err := template.New("prompts").Option("missingkey=error").ParseFS(fsys, files...)
+	if err != nil {
+		return fmt.Errorf("❌ failed to parse prompt templates: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tmpl != nil {
+		log.Printf("🔄 Prompt templates reloaded: %v", versions)
+	}
+	s.tmpl, s.versions, s.stamp = tmpl, versions, stamp
+	return nil
+}
+
+// findDir resolves a relative dir against the CWD and the project root
+// above the binary, like the config file lookup.
+func findDir(dir string) (string, bool) {
+	candidates := []string{dir}
+	if !filepath.IsAbs(dir) {
+		if exe, err := os.Executable(); err == nil {
+			candidates = append(candidates, filepath.Join(filepath.Dir(exe), "..", "..", dir))
+		}
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && info.IsDir() {
+			return c, true
+		}
+	}
+	return "", false
+}
@@ -9,24 +9,27 @@ import (
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-func InitDB(ctx context.Context, cfg *config.DatabaseConfig) {
+// InitDB checks (or applies) migrations and opens the configured database.
+// Wrap the result with NewGormStore to get the repositories.
+func InitDB(ctx context.Context, cfg *config.DatabaseConfig) *gorm.DB {
 	var db *gorm.DB
 	var err error
 
+	// Schema is owned by versioned migrations (see migrate.go)
+	if cfg.AutoMigrate {
+		if err := MigrateUp(cfg); err != nil {
+			log.Fatalf("❌ DB migration failed: %v", err)
+		}
+	}
+	if err := checkSchema(cfg); err != nil {
+		log.Fatalf("❌ DB schema check failed: %v", err)
+	}
+
 	switch cfg.Type {
 	case "sqlite":
 		db, err = newSQLiteDB(cfg.Database)
 	case "postgres":
-		db, err = newPostgresDB(ctx,
-			cfg.DBUser,
-			cfg.DBPass,
-			cfg.DBHost,
-			cfg.DBPort,
-			cfg.DBName,
-			cfg.DBSSL,
-		)
+		db, err = newPostgresDB(ctx, cfg.DSN())
 	default:
 		log.Fatalf("❌ Unsupported database type: %s", cfg.Type)
 	}
@@ -35,6 +38,6 @@ func InitDB(ctx context.Context, cfg *config.DatabaseConfig) {
 		log.Fatalf("❌ DB initialization failed: %v", err)
 	}
 
-	log.Printf("✅ Connected to %s and migrated successfully", cfg.Type)
-	DB = db
+	log.Printf("✅ Connected to %s, schema is up to date", cfg.Type)
+	return db
 }
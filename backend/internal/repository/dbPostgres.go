@@ -5,18 +5,12 @@ import (
 	"fmt"
 	"log"
 
-	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
 	"github.com/jmoiron/sqlx"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func newPostgresDB(ctx context.Context, username, password, host string, port int, database, sslMode string) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		host, port, username, password, database, sslMode,
-	)
-
+func newPostgresDB(ctx context.Context, dsn string) (*gorm.DB, error) {
 	// Optional: Verify low-level connection
 	sqlxDB, err := sqlx.Open("postgres", dsn)
 	if err != nil {
@@ -27,22 +21,11 @@ func newPostgresDB(ctx context.Context, username, password, host string, port in
 	}
 
 	// Now use GORM
-	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("❌ Failed to open with GORM: %w", err)
 	}
 
-	// Auto-migrate here
-	if err := gormDB.AutoMigrate(
-		&model.Team{},
-		&model.Player{},
-		&model.Fixture{},
-		&model.UserTeam{},
-		&model.UserTeamPlayer{},
-	); err != nil {
-		return nil, fmt.Errorf("❌ AutoMigrate failed: %w", err)
-	}
-
-	log.Println("✅ PostgreSQL connected and migrated")
+	log.Println("✅ PostgreSQL connected")
 	return gormDB, nil
 }
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const fakePGMessage = "fake postgres says no"
+
+// fakePostgres accepts connections like a server without TLS: SSL requests
+// get 'N', and any startup message is answered with a FATAL error. A driver
+// that insists on TLS fails before that error.
+func fakePostgres(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	errMsg := new(bytes.Buffer)
+	for _, f := range []string{"SFATAL", "VFATAL", "C28000", "M" + fakePGMessage} {
+		errMsg.WriteString(f + "\x00")
+	}
+	errMsg.WriteByte(0)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					var head [8]byte // length, then protocol version or request code
+					if _, err := io.ReadFull(conn, head[:]); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(head[:4])
+					if _, err := io.CopyN(io.Discard, conn, int64(n)-8); err != nil {
+						return
+					}
+					switch binary.BigEndian.Uint32(head[4:]) {
+					case 80877103, 80877104: // SSLRequest, GSSENCRequest
+						conn.Write([]byte{'N'})
+					default:
+						reply := []byte{'E', 0, 0, 0, 0}
+						binary.BigEndian.PutUint32(reply[1:], uint32(4+errMsg.Len()))
+						conn.Write(append(reply, errMsg.Bytes()...))
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// TestDatabaseURLWithoutSSLMode opens the GORM (pgx) and migrator (lib/pq)
+// connections from a DATABASE_URL without sslmode. Both should reach the
+// server's startup reply instead of one of them demanding TLS.
+func TestDatabaseURLWithoutSSLMode(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	t.Setenv("CONFIG_FILE", filepath.Join(filepath.Dir(file), "..", "..", "..", "assets", "default.toml"))
+	t.Setenv("DATABASE_URL", "postgres://fpl:secret@"+fakePostgres(t)+"/fpl")
+	t.Setenv("AI_PROVIDER", "fake")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cfg.Database.URL, "sslmode="+cfg.Database.DBSSL) {
+		t.Errorf("URL %q doesn't carry sslmode %q", cfg.Database.URL, cfg.Database.DBSSL)
+	}
+
+	_, err = gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	if err == nil || !strings.Contains(err.Error(), fakePGMessage) {
+		t.Errorf("GORM: want the server's startup error, got %v", err)
+	}
+	_, err = newPostgresDB(context.Background(), cfg.Database.DSN())
+	if err == nil || !strings.Contains(err.Error(), fakePGMessage) {
+		t.Errorf("ping: want the server's startup error, got %v", err)
+	}
+	_, err = newMigrator(&cfg.Database)
+	if err == nil || !strings.Contains(err.Error(), fakePGMessage) {
+		t.Errorf("migrator: want the server's startup error, got %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const upsertBatchSize = 500
+
+// NewGormStore returns GORM-backed repositories sharing db.
+func NewGormStore(db *gorm.DB) *Store {
+	s := &Store{
+		Players:       &gormPlayers{db},
+		Teams:         &gormTeams{db},
+		Fixtures:      &gormFixtures{db},
+		Chips:         &gormChips{db},
+		Squads:        &gormSquads{db},
+		Users:         &gormUsers{db},
+		Sessions:      &gormSessions{db},
+		Conversations: &gormConversations{db},
+		Changes:       &gormChanges{db},
+		FetchStates:   &gormFetchStates{db},
+		AIUsage:       &gormAIUsage{db},
+		Reports:       &gormReports{db},
+	}
+	s.Tx = func(ctx context.Context, fn func(tx *Store) error) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(NewGormStore(tx))
+		})
+	}
+	return s
+}
+
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func upsertByID(db *gorm.DB, columns []string) *gorm.DB {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	})
+}
+
+type gormPlayers struct{ db *gorm.DB }
+
+// Columns overwritten when a player already exists; start_price and
+// created_at are deliberately left alone.
+var playerUpsertColumns = []string{
+	"first_name", "last_name", "web_name", "team_id", "position", "current_price",
+	"selected_by_percent", "transfers_in", "transfers_in_event", "transfers_out",
+	"transfers_out_event", "event_points", "value_form", "form", "total_points",
+	"updated_at", "ict_index",
+}
+
+func (r *gormPlayers) List(ctx context.Context) ([]model.Player, error) {
+	var players []model.Player
+	err := r.db.WithContext(ctx).Find(&players).Error
+	return players, err
+}
+
+func (r *gormPlayers) Get(ctx context.Context, id uint) (model.Player, error) {
+	var p model.Player
+	err := r.db.WithContext(ctx).First(&p, id).Error
+	return p, notFound(err)
+}
+
+func (r *gormPlayers) Upsert(ctx context.Context, players []model.Player) error {
+	if len(players) == 0 {
+		return nil
+	}
+	return upsertByID(r.db.WithContext(ctx), playerUpsertColumns).
+		CreateInBatches(&players, upsertBatchSize).Error
+}
+
+type gormTeams struct{ db *gorm.DB }
+
+func (r *gormTeams) List(ctx context.Context) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.db.WithContext(ctx).Find(&teams).Error
+	return teams, err
+}
+
+func (r *gormTeams) Upsert(ctx context.Context, teams []model.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	return upsertByID(r.db.WithContext(ctx), []string{"name", "short_name", "code"}).
+		Create(&teams).Error
+}
+
+type gormFixtures struct{ db *gorm.DB }
+
+func (r *gormFixtures) List(ctx context.Context) ([]model.Fixture, error) {
+	var fixtures []model.Fixture
+	err := r.db.WithContext(ctx).Find(&fixtures).Error
+	return fixtures, err
+}
+
+func (r *gormFixtures) ListUnfinished(ctx context.Context) ([]model.Fixture, error) {
+	var fixtures []model.Fixture
+	err := r.db.WithContext(ctx).
+		Where("kickoff_time IS NOT NULL AND finished = ?", false).
+		Find(&fixtures).Error
+	return fixtures, err
+}
+
+func (r *gormFixtures) Upsert(ctx context.Context, fixtures []model.Fixture) error {
+	if len(fixtures) == 0 {
+		return nil
+	}
+	// Upsert on fixture ID so re-runs are safe
+	return upsertByID(r.db.WithContext(ctx), []string{
+		"event", "kickoff_time", "started", "finished", "provisional_start_time",
+		"team_h_id", "team_a_id", "team_h_score", "team_a_score",
+		"team_h_difficulty", "team_a_difficulty", "minutes", "pulse_id", "code",
+	}).CreateInBatches(&fixtures, upsertBatchSize).Error
+}
+
+type gormChips struct{ db *gorm.DB }
+
+func (r *gormChips) List(ctx context.Context) ([]model.Chip, error) {
+	var chips []model.Chip
+	err := r.db.WithContext(ctx).Find(&chips).Error
+	return chips, err
+}
+
+func (r *gormChips) Upsert(ctx context.Context, chips []model.Chip) error {
+	if len(chips) == 0 {
+		return nil
+	}
+	return upsertByID(r.db.WithContext(ctx), []string{
+		"name", "number", "start_event", "stop_event", "chip_type", "overrides", "updated_at",
+	}).Create(&chips).Error
+}
+
+type gormSquads struct{ db *gorm.DB }
+
+func (r *gormSquads) List(ctx context.Context) ([]model.UserTeam, error) {
+	var teams []model.UserTeam
+	err := r.db.WithContext(ctx).Find(&teams).Error
+	return teams, err
+}
+
+func (r *gormSquads) ListByUser(ctx context.Context, userID uint) ([]model.UserTeam, error) {
+	var teams []model.UserTeam
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&teams).Error
+	return teams, err
+}
+
+func (r *gormSquads) Get(ctx context.Context, id uint) (model.UserTeam, []model.UserTeamPlayer, error) {
+	var team model.UserTeam
+	if err := r.db.WithContext(ctx).First(&team, id).Error; err != nil {
+		return team, nil, notFound(err)
+	}
+	var players []model.UserTeamPlayer
+	err := r.db.WithContext(ctx).Where("user_team_id = ?", id).Find(&players).Error
+	return team, players, err
+}
+
+func (r *gormSquads) Save(ctx context.Context, team *model.UserTeam, players []model.UserTeamPlayer) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(team).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_team_id = ?", team.ID).Delete(&model.UserTeamPlayer{}).Error; err != nil {
+			return err
+		}
+		if len(players) == 0 {
+			return nil
+		}
+		for i := range players {
+			players[i].ID = 0
+			players[i].UserTeamID = team.ID
+		}
+		return tx.Create(&players).Error
+	})
+}
+
+func (r *gormSquads) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_team_id = ?", id).Delete(&model.UserTeamPlayer{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.UserTeam{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+type gormUsers struct{ db *gorm.DB }
+
+func (r *gormUsers) Create(ctx context.Context, user *model.User) error {
+	err := r.db.WithContext(ctx).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *gormUsers) Get(ctx context.Context, id uint) (model.User, error) {
+	var u model.User
+	err := r.db.WithContext(ctx).First(&u, id).Error
+	return u, notFound(err)
+}
+
+func (r *gormUsers) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	var u model.User
+	err := r.db.WithContext(ctx).First(&u, "email = ?", email).Error
+	return u, notFound(err)
+}
+
+type gormSessions struct{ db *gorm.DB }
+
+func (r *gormSessions) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *gormSessions) GetByTokenHash(ctx context.Context, tokenHash string, now time.Time) (model.Session, error) {
+	var s model.Session
+	err := r.db.WithContext(ctx).First(&s, "token_hash = ? AND expires_at > ?", tokenHash, now).Error
+	return s, notFound(err)
+}
+
+func (r *gormSessions) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	return r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&model.Session{}).Error
+}
+
+func (r *gormSessions) DeleteExpired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.Session{}).Error
+}
+
+type gormConversations struct{ db *gorm.DB }
+
+func (r *gormConversations) Create(ctx context.Context, c *model.Conversation) error {
+	return r.db.WithContext(ctx).Create(c).Error
+}
+
+func (r *gormConversations) Get(ctx context.Context, id uint) (model.Conversation, error) {
+	var c model.Conversation
+	err := r.db.WithContext(ctx).First(&c, id).Error
+	return c, notFound(err)
+}
+
+func (r *gormConversations) ListByUser(ctx context.Context, userID uint) ([]model.Conversation, error) {
+	var out []model.Conversation
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("updated_at DESC, id DESC").Find(&out).Error
+	return out, err
+}
+
+func (r *gormConversations) Update(ctx context.Context, c *model.Conversation) error {
+	res := r.db.WithContext(ctx).Model(c).Select("title", "summary", "summarized_through", "updated_at").Updates(c)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormConversations) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", id).Delete(&model.ConversationMessage{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.Conversation{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *gormConversations) AddMessages(ctx context.Context, conversationID uint, msgs []model.ConversationMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range msgs {
+			msgs[i].ID = 0
+			msgs[i].ConversationID = conversationID
+		}
+		if err := tx.Create(&msgs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Conversation{ID: conversationID}).Update("updated_at", time.Now()).Error
+	})
+}
+
+func (r *gormConversations) Messages(ctx context.Context, conversationID, afterID uint) ([]model.ConversationMessage, error) {
+	var out []model.ConversationMessage
+	err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND id > ?", conversationID, afterID).
+		Order("id").Find(&out).Error
+	return out, err
+}
+
+type gormChanges struct{ db *gorm.DB }
+
+func (r *gormChanges) Append(ctx context.Context, events []model.ChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&events, upsertBatchSize).Error
+}
+
+func (r *gormChanges) List(ctx context.Context, f ChangeFilter) ([]model.ChangeEvent, error) {
+	tx := r.db.WithContext(ctx).Where("created_at > ?", f.Since)
+	if f.AfterID > 0 {
+		tx = tx.Where("id > ?", f.AfterID)
+	}
+	if f.Kind != "" {
+		tx = tx.Where("kind = ?", f.Kind)
+	}
+	if f.Limit > 0 {
+		tx = tx.Limit(f.Limit)
+	}
+	var events []model.ChangeEvent
+	err := tx.Order("id").Find(&events).Error
+	return events, err
+}
+
+type gormFetchStates struct{ db *gorm.DB }
+
+func (r *gormFetchStates) Get(ctx context.Context, endpoint string) (model.FetchState, error) {
+	var st model.FetchState
+	err := r.db.WithContext(ctx).First(&st, "endpoint = ?", endpoint).Error
+	return st, notFound(err)
+}
+
+func (r *gormFetchStates) Save(ctx context.Context, state model.FetchState) error {
+	return r.db.WithContext(ctx).Save(&state).Error
+}
+
+func (r *gormFetchStates) LastFetchedAt(ctx context.Context) (time.Time, error) {
+	var st model.FetchState
+	err := r.db.WithContext(ctx).Order("fetched_at desc").First(&st).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	return st.FetchedAt, err
+}
+
+type gormAIUsage struct{ db *gorm.DB }
+
+func (r *gormAIUsage) Record(ctx context.Context, u *model.AIUsage) error {
+	return r.db.WithContext(ctx).Create(u).Error
+}
+
+func (r *gormAIUsage) CountUncached(ctx context.Context, userID uint, client string, since time.Time) (int, error) {
+	tx := r.db.WithContext(ctx).Model(&model.AIUsage{}).Where("cached = ? AND created_at >= ?", false, since)
+	if userID != 0 {
+		tx = tx.Where("user_id = ?", userID)
+	} else {
+		tx = tx.Where("user_id IS NULL AND client = ?", client)
+	}
+	var n int64
+	err := tx.Count(&n).Error
+	return int(n), err
+}
+
+func (r *gormAIUsage) List(ctx context.Context, since, until time.Time) ([]model.AIUsage, error) {
+	var out []model.AIUsage
+	err := r.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", since, until).
+		Order("id").Find(&out).Error
+	return out, err
+}
+
+type gormReports struct{ db *gorm.DB }
+
+func (r *gormReports) Get(ctx context.Context, gameweek int) (model.GameweekReport, error) {
+	var rep model.GameweekReport
+	err := r.db.WithContext(ctx).First(&rep, "gameweek = ?", gameweek).Error
+	return rep, notFound(err)
+}
+
+func (r *gormReports) Save(ctx context.Context, rep *model.GameweekReport) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "gameweek"}},
+		DoUpdates: clause.AssignmentColumns([]string{"markdown", "provider", "model", "prompt_version", "data_as_of", "updated_at"}),
+	}).Create(rep).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+)
+
+// TestChangesPageByID appends one import's worth of events sharing a
+// CreatedAt and pages through them with AfterID.
+func TestChangesPageByID(t *testing.T) {
+	cfg := &config.DatabaseConfig{Type: "sqlite", Database: filepath.Join(t.TempDir(), "fpl.db")}
+	if err := MigrateUp(cfg); err != nil {
+		t.Fatal(err)
+	}
+	db, err := newSQLiteDB(cfg.Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewGormStore(db)
+	ctx := context.Background()
+
+	at := time.Now().UTC()
+	var batch []model.ChangeEvent
+	for i := 1; i <= 5; i++ {
+		batch = append(batch, model.ChangeEvent{Kind: "price_change", EntityType: "player", EntityID: uint(i), Summary: fmt.Sprint("player ", i), CreatedAt: at})
+	}
+	if err := store.Changes.Append(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []uint
+	f := ChangeFilter{Since: at.Add(-time.Minute), Limit: 2}
+	for range 5 {
+		page, err := store.Changes.List(ctx, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, e := range page {
+			seen = append(seen, e.EntityID)
+		}
+		f.AfterID = page[len(page)-1].ID
+	}
+	if fmt.Sprint(seen) != "[1 2 3 4 5]" {
+		t.Errorf("paging by id saw %v, want all five events once", seen)
+	}
+}
@@ -0,0 +1,553 @@
+// Package memory provides map-backed repositories for handler tests and
+// local experiments. All repositories are safe for concurrent use.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// NewStore returns an empty in-memory store.
+func NewStore() *repository.Store {
+	s := &repository.Store{
+		Players:       &players{rows: map[uint]model.Player{}},
+		Teams:         &teams{rows: map[uint]model.Team{}},
+		Fixtures:      &fixtures{rows: map[int]model.Fixture{}},
+		Chips:         &chips{rows: map[uint]model.Chip{}},
+		Squads:        &squads{teams: map[uint]model.UserTeam{}, picks: map[uint][]model.UserTeamPlayer{}},
+		Users:         &users{rows: map[uint]model.User{}},
+		Sessions:      &sessions{rows: map[string]model.Session{}},
+		Conversations: &conversations{rows: map[uint]model.Conversation{}, msgs: map[uint][]model.ConversationMessage{}},
+		Changes:       &changes{},
+		FetchStates:   &fetchStates{rows: map[string]model.FetchState{}},
+		AIUsage:       &aiUsage{},
+		Reports:       &reports{rows: map[int]model.GameweekReport{}},
+	}
+	s.Tx = newTx(s)
+	return s
+}
+
+// sortedValues returns map values ordered by key.
+func sortedValues[K int | uint | string, V any](m map[K]V) []V {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	out := make([]V, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, m[k])
+	}
+	return out
+}
+
+type players struct {
+	mu   sync.RWMutex
+	rows map[uint]model.Player
+}
+
+func (r *players) List(ctx context.Context) ([]model.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedValues(r.rows), nil
+}
+
+func (r *players) Get(ctx context.Context, id uint) (model.Player, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.rows[id]
+	if !ok {
+		return p, repository.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *players) Upsert(ctx context.Context, in []model.Player) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range in {
+		if old, ok := r.rows[p.ID]; ok {
+			p.StartPrice = old.StartPrice
+			p.CreatedAt = old.CreatedAt
+		}
+		r.rows[p.ID] = p
+	}
+	return nil
+}
+
+type teams struct {
+	mu   sync.RWMutex
+	rows map[uint]model.Team
+}
+
+func (r *teams) List(ctx context.Context) ([]model.Team, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedValues(r.rows), nil
+}
+
+func (r *teams) Upsert(ctx context.Context, in []model.Team) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range in {
+		r.rows[t.ID] = t
+	}
+	return nil
+}
+
+type fixtures struct {
+	mu   sync.RWMutex
+	rows map[int]model.Fixture
+}
+
+func (r *fixtures) List(ctx context.Context) ([]model.Fixture, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedValues(r.rows), nil
+}
+
+func (r *fixtures) ListUnfinished(ctx context.Context) ([]model.Fixture, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.Fixture
+	for _, f := range sortedValues(r.rows) {
+		if f.KickoffTime != nil && !f.Finished {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (r *fixtures) Upsert(ctx context.Context, in []model.Fixture) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range in {
+		r.rows[f.ID] = f
+	}
+	return nil
+}
+
+type chips struct {
+	mu   sync.RWMutex
+	rows map[uint]model.Chip
+}
+
+func (r *chips) List(ctx context.Context) ([]model.Chip, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedValues(r.rows), nil
+}
+
+func (r *chips) Upsert(ctx context.Context, in []model.Chip) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range in {
+		if old, ok := r.rows[c.ID]; ok {
+			c.CreatedAt = old.CreatedAt
+		}
+		r.rows[c.ID] = c
+	}
+	return nil
+}
+
+type squads struct {
+	mu     sync.RWMutex
+	nextID uint
+	teams  map[uint]model.UserTeam
+	picks  map[uint][]model.UserTeamPlayer
+}
+
+func (r *squads) List(ctx context.Context) ([]model.UserTeam, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedValues(r.teams), nil
+}
+
+func (r *squads) ListByUser(ctx context.Context, userID uint) ([]model.UserTeam, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.UserTeam
+	for _, t := range sortedValues(r.teams) {
+		if t.UserID != nil && *t.UserID == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *squads) Get(ctx context.Context, id uint) (model.UserTeam, []model.UserTeamPlayer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.teams[id]
+	if !ok {
+		return t, nil, repository.ErrNotFound
+	}
+	return t, append([]model.UserTeamPlayer(nil), r.picks[id]...), nil
+}
+
+func (r *squads) Save(ctx context.Context, team *model.UserTeam, in []model.UserTeamPlayer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if team.ID == 0 {
+		r.nextID++
+		team.ID = r.nextID
+	} else if team.ID > r.nextID {
+		r.nextID = team.ID
+	}
+	r.teams[team.ID] = *team
+
+	picks := make([]model.UserTeamPlayer, len(in))
+	for i, p := range in {
+		p.ID = uint(i + 1)
+		p.UserTeamID = team.ID
+		picks[i] = p
+	}
+	r.picks[team.ID] = picks
+	return nil
+}
+
+func (r *squads) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.teams[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.teams, id)
+	delete(r.picks, id)
+	return nil
+}
+
+type users struct {
+	mu     sync.RWMutex
+	nextID uint
+	rows   map[uint]model.User
+}
+
+func (r *users) Create(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.rows {
+		if u.Email == user.Email {
+			return repository.ErrDuplicate
+		}
+	}
+	r.nextID++
+	user.ID = r.nextID
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.rows[user.ID] = *user
+	return nil
+}
+
+func (r *users) Get(ctx context.Context, id uint) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	u, ok := r.rows[id]
+	if !ok {
+		return u, repository.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *users) GetByEmail(ctx context.Context, email string) (model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, u := range r.rows {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, repository.ErrNotFound
+}
+
+// sessions is keyed by token hash.
+type sessions struct {
+	mu     sync.RWMutex
+	nextID uint
+	rows   map[string]model.Session
+}
+
+func (r *sessions) Create(ctx context.Context, session *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	session.ID = r.nextID
+	session.CreatedAt = time.Now()
+	r.rows[session.TokenHash] = *session
+	return nil
+}
+
+func (r *sessions) GetByTokenHash(ctx context.Context, tokenHash string, now time.Time) (model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.rows[tokenHash]
+	if !ok || !s.ExpiresAt.After(now) {
+		return model.Session{}, repository.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *sessions) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rows, tokenHash)
+	return nil
+}
+
+func (r *sessions) DeleteExpired(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, s := range r.rows {
+		if !s.ExpiresAt.After(now) {
+			delete(r.rows, k)
+		}
+	}
+	return nil
+}
+
+type conversations struct {
+	mu        sync.RWMutex
+	nextID    uint
+	nextMsgID uint
+	rows      map[uint]model.Conversation
+	msgs      map[uint][]model.ConversationMessage
+}
+
+func (r *conversations) Create(ctx context.Context, c *model.Conversation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	c.ID = r.nextID
+	now := time.Now()
+	c.CreatedAt, c.UpdatedAt = now, now
+	r.rows[c.ID] = *c
+	return nil
+}
+
+func (r *conversations) Get(ctx context.Context, id uint) (model.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.rows[id]
+	if !ok {
+		return c, repository.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *conversations) ListByUser(ctx context.Context, userID uint) ([]model.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.Conversation
+	for _, c := range r.rows {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].UpdatedAt.Equal(out[j].UpdatedAt) {
+			return out[i].UpdatedAt.After(out[j].UpdatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+func (r *conversations) Update(ctx context.Context, c *model.Conversation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.rows[c.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	old.Title, old.Summary, old.SummarizedThrough = c.Title, c.Summary, c.SummarizedThrough
+	old.UpdatedAt = time.Now()
+	c.UpdatedAt = old.UpdatedAt
+	r.rows[c.ID] = old
+	return nil
+}
+
+func (r *conversations) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rows[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.rows, id)
+	delete(r.msgs, id)
+	return nil
+}
+
+func (r *conversations) AddMessages(ctx context.Context, conversationID uint, msgs []model.ConversationMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.rows[conversationID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	for i := range msgs {
+		r.nextMsgID++
+		msgs[i].ID = r.nextMsgID
+		msgs[i].ConversationID = conversationID
+		msgs[i].CreatedAt = now
+	}
+	r.msgs[conversationID] = append(r.msgs[conversationID], msgs...)
+	c.UpdatedAt = now
+	r.rows[conversationID] = c
+	return nil
+}
+
+func (r *conversations) Messages(ctx context.Context, conversationID, afterID uint) ([]model.ConversationMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.ConversationMessage
+	for _, m := range r.msgs[conversationID] {
+		if m.ID > afterID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+type changes struct {
+	mu   sync.RWMutex
+	rows []model.ChangeEvent
+}
+
+func (r *changes) Append(ctx context.Context, in []model.ChangeEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range in {
+		e.ID = uint(len(r.rows) + 1)
+		r.rows = append(r.rows, e)
+	}
+	return nil
+}
+
+func (r *changes) List(ctx context.Context, f repository.ChangeFilter) ([]model.ChangeEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.ChangeEvent
+	for _, e := range r.rows {
+		if !e.CreatedAt.After(f.Since) || e.ID <= f.AfterID || (f.Kind != "" && e.Kind != f.Kind) {
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+type fetchStates struct {
+	mu   sync.RWMutex
+	rows map[string]model.FetchState
+}
+
+func (r *fetchStates) Get(ctx context.Context, endpoint string) (model.FetchState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	st, ok := r.rows[endpoint]
+	if !ok {
+		return st, repository.ErrNotFound
+	}
+	return st, nil
+}
+
+func (r *fetchStates) Save(ctx context.Context, state model.FetchState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows[state.Endpoint] = state
+	return nil
+}
+
+func (r *fetchStates) LastFetchedAt(ctx context.Context) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var last time.Time
+	for _, st := range r.rows {
+		if st.FetchedAt.After(last) {
+			last = st.FetchedAt
+		}
+	}
+	return last, nil
+}
+
+type aiUsage struct {
+	mu   sync.RWMutex
+	rows []model.AIUsage
+}
+
+func (r *aiUsage) Record(ctx context.Context, u *model.AIUsage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u.ID = uint(len(r.rows) + 1)
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	r.rows = append(r.rows, *u)
+	return nil
+}
+
+func (r *aiUsage) CountUncached(ctx context.Context, userID uint, client string, since time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, u := range r.rows {
+		if u.Cached || u.CreatedAt.Before(since) {
+			continue
+		}
+		if (userID != 0 && u.UserID != nil && *u.UserID == userID) ||
+			(userID == 0 && u.UserID == nil && u.Client == client) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *aiUsage) List(ctx context.Context, since, until time.Time) ([]model.AIUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []model.AIUsage
+	for _, u := range r.rows {
+		if !u.CreatedAt.Before(since) && u.CreatedAt.Before(until) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+type reports struct {
+	mu   sync.RWMutex
+	rows map[int]model.GameweekReport // by gameweek
+}
+
+func (r *reports) Get(ctx context.Context, gameweek int) (model.GameweekReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rep, ok := r.rows[gameweek]
+	if !ok {
+		return rep, repository.ErrNotFound
+	}
+	return rep, nil
+}
+
+func (r *reports) Save(ctx context.Context, rep *model.GameweekReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if old, ok := r.rows[rep.Gameweek]; ok {
+		rep.ID, rep.CreatedAt = old.ID, old.CreatedAt
+	} else {
+		rep.ID, rep.CreatedAt = uint(len(r.rows)+1), now
+	}
+	rep.UpdatedAt = now
+	r.rows[rep.Gameweek] = *rep
+	return nil
+}
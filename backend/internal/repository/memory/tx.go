@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// snapshotter copies a repository's rows and returns a func putting them back.
+type snapshotter interface {
+	snapshot() (restore func())
+}
+
+// newTx gives the store all-or-nothing transactions: every repository is
+// copied up front and restored if fn fails. Transactions run one at a time,
+// but writes made outside one meanwhile are lost on rollback; fine for tests.
+func newTx(s *repository.Store) func(ctx context.Context, fn func(tx *repository.Store) error) error {
+	var mu sync.Mutex
+	repos := []any{s.Players, s.Teams, s.Fixtures, s.Chips, s.Squads, s.Users, s.Sessions,
+		s.Conversations, s.Changes, s.FetchStates, s.AIUsage, s.Reports}
+	return func(ctx context.Context, fn func(tx *repository.Store) error) error {
+		mu.Lock()
+		defer mu.Unlock()
+		var restore []func()
+		for _, r := range repos {
+			restore = append(restore, r.(snapshotter).snapshot())
+		}
+		// nested WithTx calls join this transaction
+		tx := *s
+		tx.Tx = nil
+		if err := fn(&tx); err != nil {
+			for _, undo := range restore {
+				undo()
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func (r *players) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *teams) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *fixtures) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *chips) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *squads) snapshot() func() {
+	r.mu.RLock()
+	nextID, teams, picks := r.nextID, maps.Clone(r.teams), maps.Clone(r.picks)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.nextID, r.teams, r.picks = nextID, teams, picks; r.mu.Unlock() }
+}
+
+func (r *users) snapshot() func() {
+	r.mu.RLock()
+	nextID, rows := r.nextID, maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.nextID, r.rows = nextID, rows; r.mu.Unlock() }
+}
+
+func (r *sessions) snapshot() func() {
+	r.mu.RLock()
+	nextID, rows := r.nextID, maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.nextID, r.rows = nextID, rows; r.mu.Unlock() }
+}
+
+func (r *conversations) snapshot() func() {
+	r.mu.RLock()
+	nextID, nextMsgID, rows, msgs := r.nextID, r.nextMsgID, maps.Clone(r.rows), maps.Clone(r.msgs)
+	r.mu.RUnlock()
+	return func() {
+		r.mu.Lock()
+		r.nextID, r.nextMsgID, r.rows, r.msgs = nextID, nextMsgID, rows, msgs
+		r.mu.Unlock()
+	}
+}
+
+func (r *changes) snapshot() func() {
+	r.mu.RLock()
+	rows := slices.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *fetchStates) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *aiUsage) snapshot() func() {
+	r.mu.RLock()
+	rows := slices.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
+
+func (r *reports) snapshot() func() {
+	r.mu.RLock()
+	rows := maps.Clone(r.rows)
+	r.mu.RUnlock()
+	return func() { r.mu.Lock(); r.rows = rows; r.mu.Unlock() }
+}
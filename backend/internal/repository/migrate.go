@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// MigrationStatus describes where a database stands relative to the
+// migrations compiled into this binary.
+type MigrationStatus struct {
+	Current uint // 0 = nothing applied yet
+	Latest  uint
+	Dirty   bool
+}
+
+func (s MigrationStatus) String() string {
+	return fmt.Sprintf("schema version %d (dirty=%t), binary expects %d", s.Current, s.Dirty, s.Latest)
+}
+
+// MigrateUp applies all pending migrations.
+func MigrateUp(cfg *config.DatabaseConfig) error {
+	m, err := newMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("❌ migrate up failed: %w", err)
+	}
+	return nil
+}
+
+// MigrateDown rolls back the given number of migrations; steps <= 0 rolls
+// back everything.
+func MigrateDown(cfg *config.DatabaseConfig, steps int) error {
+	m, err := newMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if steps <= 0 {
+		err = m.Down()
+	} else {
+		err = m.Steps(-steps)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("❌ migrate down failed: %w", err)
+	}
+	return nil
+}
+
+// MigrateForce marks version as applied and clears the dirty flag, for
+// recovering after a failed migration was fixed by hand.
+func MigrateForce(cfg *config.DatabaseConfig, version int) error {
+	m, err := newMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.Force(version)
+}
+
+func GetMigrationStatus(cfg *config.DatabaseConfig) (MigrationStatus, error) {
+	latest, err := latestMigration(cfg.Type)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+
+	m, err := newMigrator(cfg)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	defer m.Close()
+
+	st := MigrationStatus{Latest: latest}
+	v, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+	case err != nil:
+		return st, fmt.Errorf("❌ read schema version: %w", err)
+	default:
+		st.Current, st.Dirty = v, dirty
+	}
+	return st, nil
+}
+
+// checkSchema refuses to start against a schema that is behind, ahead of,
+// or half-way through the migrations this binary was built with.
+func checkSchema(cfg *config.DatabaseConfig) error {
+	st, err := GetMigrationStatus(cfg)
+	if err != nil {
+		return err
+	}
+	if st.Dirty || st.Current != st.Latest {
+		return fmt.Errorf("❌ %s; run `migrate up` (or `migrate force`) first", st)
+	}
+	return nil
+}
+
+// newMigrator opens its own connection: closing a migrate.Migrate also
+// closes the database handle it was given.
+func newMigrator(cfg *config.DatabaseConfig) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrationsFS, "migrations/"+cfg.Type)
+	if err != nil {
+		return nil, fmt.Errorf("❌ load %s migrations: %w", cfg.Type, err)
+	}
+
+	var (
+		sqlDB  *sql.DB
+		driver database.Driver
+	)
+	switch cfg.Type {
+	case "sqlite":
+		path, err := sqlitePath(cfg.Database)
+		if err != nil {
+			return nil, err
+		}
+		if sqlDB, err = sql.Open("sqlite3", path); err != nil {
+			return nil, err
+		}
+		driver, err = sqlite3.WithInstance(sqlDB, &sqlite3.Config{})
+		if err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("❌ migrate driver: %w", err)
+		}
+	case "postgres":
+		if sqlDB, err = sql.Open("postgres", cfg.DSN()); err != nil {
+			return nil, err
+		}
+		driver, err = postgres.WithInstance(sqlDB, &postgres.Config{})
+		if err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("❌ migrate driver: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("❌ unsupported database type: %s", cfg.Type)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, cfg.Type, driver)
+	if err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("❌ create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return m, nil
+}
+
+// latestMigration returns the highest version among the embedded files.
+func latestMigration(dbType string) (uint, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations/"+dbType)
+	if err != nil {
+		return 0, fmt.Errorf("❌ load %s migrations: %w", dbType, err)
+	}
+	var versions []uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, uint(v))
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions[len(versions)-1], nil
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	log.Printf("🧱 "+format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
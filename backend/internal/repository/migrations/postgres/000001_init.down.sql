@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "user_team_players";
+DROP TABLE IF EXISTS "user_teams";
+DROP TABLE IF EXISTS "chips";
+DROP TABLE IF EXISTS "fixtures";
+DROP TABLE IF EXISTS "Players";
+DROP TABLE IF EXISTS "teams";
@@ -0,0 +1,83 @@
+-- Baseline schema. IF NOT EXISTS lets databases created by the old
+-- gorm.AutoMigrate startup adopt versioned migrations in place.
+CREATE TABLE IF NOT EXISTS "teams" (
+    "id" bigserial PRIMARY KEY,
+    "name" varchar(100) NOT NULL,
+    "short_name" varchar(10) NOT NULL,
+    "code" varchar(10)
+);
+
+CREATE TABLE IF NOT EXISTS "Players" (
+    "id" bigserial PRIMARY KEY,
+    "first_name" varchar(100) NOT NULL,
+    "last_name" varchar(100) NOT NULL,
+    "web_name" text NOT NULL,
+    "team_id" bigint NOT NULL,
+    "position" varchar(10) NOT NULL,
+    "start_price" decimal NOT NULL,
+    "current_price" decimal NOT NULL,
+    "selected_by_percent" decimal,
+    "transfers_in" bigint,
+    "transfers_in_event" bigint,
+    "transfers_out" bigint,
+    "transfers_out_event" bigint,
+    "event_points" bigint NOT NULL,
+    "value_form" decimal,
+    "form" varchar(10),
+    "total_points" bigint NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "ict_index" text
+);
+
+CREATE TABLE IF NOT EXISTS "fixtures" (
+    "id" bigserial PRIMARY KEY,
+    "event" bigint,
+    "kickoff_time" timestamptz,
+    "started" boolean,
+    "finished" boolean,
+    "provisional_start_time" boolean,
+    "team_h_id" bigint NOT NULL,
+    "team_a_id" bigint NOT NULL,
+    "team_h_score" bigint,
+    "team_a_score" bigint,
+    "team_h_difficulty" bigint NOT NULL,
+    "team_a_difficulty" bigint NOT NULL,
+    "minutes" bigint,
+    "pulse_id" bigint,
+    "code" bigint,
+    CONSTRAINT "fk_fixtures_team_h" FOREIGN KEY ("team_h_id") REFERENCES "teams"("id"),
+    CONSTRAINT "fk_fixtures_team_a" FOREIGN KEY ("team_a_id") REFERENCES "teams"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_fixtures_event" ON "fixtures"("event");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_team_h_id" ON "fixtures"("team_h_id");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_team_a_id" ON "fixtures"("team_a_id");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_code" ON "fixtures"("code");
+
+-- Never created on Postgres by the old AutoMigrate list.
+CREATE TABLE IF NOT EXISTS "chips" (
+    "id" bigserial PRIMARY KEY,
+    "name" varchar(50) NOT NULL,
+    "number" bigint NOT NULL,
+    "start_event" bigint NOT NULL,
+    "stop_event" bigint NOT NULL,
+    "chip_type" varchar(32) NOT NULL,
+    "overrides" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS "user_teams" (
+    "id" bigserial PRIMARY KEY,
+    "user_name" text NOT NULL,
+    "points" bigint
+);
+
+CREATE TABLE IF NOT EXISTS "user_team_players" (
+    "id" bigserial PRIMARY KEY,
+    "user_team_id" bigint NOT NULL,
+    "player_id" bigint NOT NULL,
+    "is_captain" boolean,
+    "is_vice" boolean,
+    "is_starting" boolean
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "change_events";
+DROP TABLE IF EXISTS "fetch_states";
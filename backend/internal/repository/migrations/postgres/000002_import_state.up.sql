@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "fetch_states" (
+    "endpoint" varchar(64) PRIMARY KEY,
+    "url" text NOT NULL,
+    "e_tag" varchar(255),
+    "last_modified" varchar(64),
+    "fetched_at" timestamptz,
+    "snapshot_path" text
+);
+
+CREATE TABLE IF NOT EXISTS "change_events" (
+    "id" bigserial PRIMARY KEY,
+    "kind" varchar(32) NOT NULL,
+    "entity_type" varchar(16) NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "old_value" varchar(64),
+    "new_value" varchar(64),
+    "summary" text NOT NULL,
+    "created_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_change_events_kind" ON "change_events"("kind");
+CREATE INDEX IF NOT EXISTS "idx_change_events_entity_id" ON "change_events"("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_change_events_created_at" ON "change_events"("created_at");
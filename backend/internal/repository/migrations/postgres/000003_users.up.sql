@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial PRIMARY KEY,
+    "email" varchar(254) NOT NULL,
+    "display_name" varchar(100) NOT NULL,
+    "password_hash" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email");
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+    "token_hash" char(64) NOT NULL,
+    "user_agent" varchar(255),
+    "ip" varchar(64),
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token_hash" ON "sessions"("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions"("user_id");
+CREATE INDEX IF NOT EXISTS "idx_sessions_expires_at" ON "sessions"("expires_at");
+
+-- Existing squads keep user_id NULL: they predate accounts and belong to nobody.
+ALTER TABLE "user_teams" ADD COLUMN IF NOT EXISTS "user_id" bigint REFERENCES "users"("id") ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS "idx_user_teams_user_id" ON "user_teams"("user_id");
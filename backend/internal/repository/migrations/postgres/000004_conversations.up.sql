@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "conversations" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+    "title" varchar(200),
+    "summary" text,
+    "summarized_through" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_conversations_user_id" ON "conversations"("user_id");
+
+CREATE TABLE IF NOT EXISTS "conversation_messages" (
+    "id" bigserial PRIMARY KEY,
+    "conversation_id" bigint NOT NULL REFERENCES "conversations"("id") ON DELETE CASCADE,
+    "role" varchar(16) NOT NULL,
+    "content" text NOT NULL,
+    "model" varchar(100),
+    "created_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_conversation_messages_conversation_id" ON "conversation_messages"("conversation_id");
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "ai_usages" (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint REFERENCES "users"("id") ON DELETE SET NULL,
+    "client" varchar(64),
+    "endpoint" varchar(32) NOT NULL,
+    "provider" varchar(32),
+    "model" varchar(100),
+    "prompt_tokens" bigint NOT NULL DEFAULT 0,
+    "completion_tokens" bigint NOT NULL DEFAULT 0,
+    "cached" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz
+);
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_user_id" ON "ai_usages"("user_id");
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_client" ON "ai_usages"("client");
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_created_at" ON "ai_usages"("created_at");
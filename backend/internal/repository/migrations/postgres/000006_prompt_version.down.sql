@@ -0,0 +1 @@
+ALTER TABLE "ai_usages" DROP COLUMN IF EXISTS "prompt_version";
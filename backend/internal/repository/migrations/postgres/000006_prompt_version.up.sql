@@ -0,0 +1 @@
+ALTER TABLE "ai_usages" ADD COLUMN IF NOT EXISTS "prompt_version" varchar(100);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "gameweek_reports" (
+    "id" bigserial PRIMARY KEY,
+    "gameweek" bigint NOT NULL,
+    "markdown" text NOT NULL,
+    "provider" varchar(32),
+    "model" varchar(100),
+    "prompt_version" varchar(100),
+    "data_as_of" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_gameweek_reports_gameweek" ON "gameweek_reports"("gameweek");
@@ -0,0 +1,82 @@
+-- Baseline schema. IF NOT EXISTS lets databases created by the old
+-- gorm.AutoMigrate startup adopt versioned migrations in place.
+CREATE TABLE IF NOT EXISTS "teams" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "short_name" text NOT NULL,
+    "code" text
+);
+
+CREATE TABLE IF NOT EXISTS "Players" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "web_name" text NOT NULL,
+    "team_id" integer NOT NULL,
+    "position" text NOT NULL,
+    "start_price" real NOT NULL,
+    "current_price" real NOT NULL,
+    "selected_by_percent" real,
+    "transfers_in" integer,
+    "transfers_in_event" integer,
+    "transfers_out" integer,
+    "transfers_out_event" integer,
+    "event_points" integer NOT NULL,
+    "value_form" real,
+    "form" text,
+    "total_points" integer NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "ict_index" text
+);
+
+CREATE TABLE IF NOT EXISTS "fixtures" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "event" integer,
+    "kickoff_time" datetime,
+    "started" numeric,
+    "finished" numeric,
+    "provisional_start_time" numeric,
+    "team_h_id" integer NOT NULL,
+    "team_a_id" integer NOT NULL,
+    "team_h_score" integer,
+    "team_a_score" integer,
+    "team_h_difficulty" integer NOT NULL,
+    "team_a_difficulty" integer NOT NULL,
+    "minutes" integer,
+    "pulse_id" integer,
+    "code" integer,
+    CONSTRAINT "fk_fixtures_team_h" FOREIGN KEY ("team_h_id") REFERENCES "teams"("id"),
+    CONSTRAINT "fk_fixtures_team_a" FOREIGN KEY ("team_a_id") REFERENCES "teams"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_fixtures_event" ON "fixtures"("event");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_team_h_id" ON "fixtures"("team_h_id");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_team_a_id" ON "fixtures"("team_a_id");
+CREATE INDEX IF NOT EXISTS "idx_fixtures_code" ON "fixtures"("code");
+
+CREATE TABLE IF NOT EXISTS "chips" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "number" integer NOT NULL,
+    "start_event" integer NOT NULL,
+    "stop_event" integer NOT NULL,
+    "chip_type" text NOT NULL,
+    "overrides" JSON,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+
+CREATE TABLE IF NOT EXISTS "user_teams" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_name" text NOT NULL,
+    "points" integer
+);
+
+CREATE TABLE IF NOT EXISTS "user_team_players" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_team_id" integer NOT NULL,
+    "player_id" integer NOT NULL,
+    "is_captain" numeric,
+    "is_vice" numeric,
+    "is_starting" numeric
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "fetch_states" (
+    "endpoint" text NOT NULL,
+    "url" text NOT NULL,
+    "e_tag" text,
+    "last_modified" text,
+    "fetched_at" datetime,
+    "snapshot_path" text,
+    PRIMARY KEY ("endpoint")
+);
+
+CREATE TABLE IF NOT EXISTS "change_events" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "kind" text NOT NULL,
+    "entity_type" text NOT NULL,
+    "entity_id" integer NOT NULL,
+    "old_value" text,
+    "new_value" text,
+    "summary" text NOT NULL,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_change_events_kind" ON "change_events"("kind");
+CREATE INDEX IF NOT EXISTS "idx_change_events_entity_id" ON "change_events"("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_change_events_created_at" ON "change_events"("created_at");
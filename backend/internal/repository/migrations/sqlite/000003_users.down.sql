@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_user_teams_user_id";
+ALTER TABLE "user_teams" DROP COLUMN "user_id";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "email" text NOT NULL,
+    "display_name" text NOT NULL,
+    "password_hash" text NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email");
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL,
+    "token_hash" text NOT NULL,
+    "user_agent" text,
+    "ip" text,
+    "expires_at" datetime NOT NULL,
+    "created_at" datetime,
+    CONSTRAINT "fk_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token_hash" ON "sessions"("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions"("user_id");
+CREATE INDEX IF NOT EXISTS "idx_sessions_expires_at" ON "sessions"("expires_at");
+
+-- Existing squads keep user_id NULL: they predate accounts and belong to nobody.
+-- No REFERENCES here so the down migration can DROP COLUMN on SQLite.
+ALTER TABLE "user_teams" ADD COLUMN "user_id" integer;
+CREATE INDEX IF NOT EXISTS "idx_user_teams_user_id" ON "user_teams"("user_id");
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "conversations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL,
+    "title" text,
+    "summary" text,
+    "summarized_through" integer NOT NULL DEFAULT 0,
+    "created_at" datetime,
+    "updated_at" datetime,
+    CONSTRAINT "fk_conversations_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_conversations_user_id" ON "conversations"("user_id");
+
+CREATE TABLE IF NOT EXISTS "conversation_messages" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "conversation_id" integer NOT NULL,
+    "role" text NOT NULL,
+    "content" text NOT NULL,
+    "model" text,
+    "created_at" datetime,
+    CONSTRAINT "fk_conversation_messages_conversation" FOREIGN KEY ("conversation_id") REFERENCES "conversations"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_conversation_messages_conversation_id" ON "conversation_messages"("conversation_id");
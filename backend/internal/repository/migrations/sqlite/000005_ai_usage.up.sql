@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "ai_usages" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer,
+    "client" text,
+    "endpoint" text NOT NULL,
+    "provider" text,
+    "model" text,
+    "prompt_tokens" integer NOT NULL DEFAULT 0,
+    "completion_tokens" integer NOT NULL DEFAULT 0,
+    "cached" numeric NOT NULL DEFAULT false,
+    "created_at" datetime,
+    CONSTRAINT "fk_ai_usages_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_user_id" ON "ai_usages"("user_id");
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_client" ON "ai_usages"("client");
+CREATE INDEX IF NOT EXISTS "idx_ai_usages_created_at" ON "ai_usages"("created_at");
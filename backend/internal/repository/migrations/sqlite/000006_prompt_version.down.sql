@@ -0,0 +1 @@
+ALTER TABLE "ai_usages" DROP COLUMN "prompt_version";
@@ -0,0 +1 @@
+ALTER TABLE "ai_usages" ADD COLUMN "prompt_version" text;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "gameweek_reports";
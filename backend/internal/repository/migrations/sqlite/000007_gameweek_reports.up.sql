@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "gameweek_reports" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "gameweek" integer NOT NULL,
+    "markdown" text NOT NULL,
+    "provider" text,
+    "model" text,
+    "prompt_version" text,
+    "data_as_of" datetime,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_gameweek_reports_gameweek" ON "gameweek_reports"("gameweek");
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/model"
+)
+
+// ErrNotFound is returned by Get-style lookups when no row matches.
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate is returned when a unique column (e.g. a user's email) is taken.
+var ErrDuplicate = errors.New("duplicate record")
+
+type PlayerRepository interface {
+	List(ctx context.Context) ([]model.Player, error)
+	Get(ctx context.Context, id uint) (model.Player, error)
+	// Upsert inserts or overwrites players by ID. StartPrice and CreatedAt of
+	// existing rows are kept.
+	Upsert(ctx context.Context, players []model.Player) error
+}
+
+type TeamRepository interface {
+	List(ctx context.Context) ([]model.Team, error)
+	Upsert(ctx context.Context, teams []model.Team) error
+}
+
+type FixtureRepository interface {
+	List(ctx context.Context) ([]model.Fixture, error)
+	// ListUnfinished returns fixtures with a kickoff time that are not finished.
+	ListUnfinished(ctx context.Context) ([]model.Fixture, error)
+	Upsert(ctx context.Context, fixtures []model.Fixture) error
+}
+
+type ChipRepository interface {
+	List(ctx context.Context) ([]model.Chip, error)
+	Upsert(ctx context.Context, chips []model.Chip) error
+}
+
+// SquadRepository stores user teams together with their picks.
+type SquadRepository interface {
+	List(ctx context.Context) ([]model.UserTeam, error)
+	ListByUser(ctx context.Context, userID uint) ([]model.UserTeam, error)
+	Get(ctx context.Context, id uint) (model.UserTeam, []model.UserTeamPlayer, error)
+	// Save creates the team when its ID is zero, otherwise updates it, and
+	// replaces its picks with players.
+	Save(ctx context.Context, team *model.UserTeam, players []model.UserTeamPlayer) error
+	Delete(ctx context.Context, id uint) error
+}
+
+type UserRepository interface {
+	// Create fails with ErrDuplicate when the email is taken.
+	Create(ctx context.Context, user *model.User) error
+	Get(ctx context.Context, id uint) (model.User, error)
+	GetByEmail(ctx context.Context, email string) (model.User, error)
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	// GetByTokenHash ignores expired sessions.
+	GetByTokenHash(ctx context.Context, tokenHash string, now time.Time) (model.Session, error)
+	DeleteByTokenHash(ctx context.Context, tokenHash string) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// ConversationRepository stores assistant chats and their messages.
+type ConversationRepository interface {
+	Create(ctx context.Context, c *model.Conversation) error
+	Get(ctx context.Context, id uint) (model.Conversation, error)
+	// ListByUser returns the most recently active conversations first.
+	ListByUser(ctx context.Context, userID uint) ([]model.Conversation, error)
+	// Update saves the title and summary fields and bumps UpdatedAt.
+	Update(ctx context.Context, c *model.Conversation) error
+	// Delete removes the conversation with its messages.
+	Delete(ctx context.Context, id uint) error
+	// AddMessages appends messages, filling in their IDs, and bumps the
+	// conversation's UpdatedAt.
+	AddMessages(ctx context.Context, conversationID uint, msgs []model.ConversationMessage) error
+	// Messages returns messages with ID > afterID, oldest first.
+	Messages(ctx context.Context, conversationID, afterID uint) ([]model.ConversationMessage, error)
+}
+
+type ChangeFilter struct {
+	Since time.Time
+	// Only events with a larger ID. One import's events share a CreatedAt,
+	// so this is the cursor for paging through a batch bigger than Limit.
+	AfterID uint
+	Kind    string // optional
+	Limit   int
+}
+
+type ChangeRepository interface {
+	Append(ctx context.Context, events []model.ChangeEvent) error
+	// List returns matching events oldest first.
+	List(ctx context.Context, f ChangeFilter) ([]model.ChangeEvent, error)
+}
+
+type FetchStateRepository interface {
+	Get(ctx context.Context, endpoint string) (model.FetchState, error)
+	Save(ctx context.Context, state model.FetchState) error
+	// LastFetchedAt is the newest FetchedAt over all endpoints; zero before
+	// the first import.
+	LastFetchedAt(ctx context.Context) (time.Time, error)
+}
+
+type AIUsageRepository interface {
+	Record(ctx context.Context, u *model.AIUsage) error
+	// CountUncached counts requests that reached the provider since the
+	// given time, by userID or, when that is zero, by client.
+	CountUncached(ctx context.Context, userID uint, client string, since time.Time) (int, error)
+	// List returns usage created in [since, until), oldest first.
+	List(ctx context.Context, since, until time.Time) ([]model.AIUsage, error)
+}
+
+type GameweekReportRepository interface {
+	Get(ctx context.Context, gameweek int) (model.GameweekReport, error)
+	// Save inserts the report or replaces the one for the same gameweek.
+	Save(ctx context.Context, r *model.GameweekReport) error
+}
+
+// Store bundles every repository the application needs so it can be passed
+// around as one dependency.
+type Store struct {
+	Players       PlayerRepository
+	Teams         TeamRepository
+	Fixtures      FixtureRepository
+	Chips         ChipRepository
+	Squads        SquadRepository
+	Users         UserRepository
+	Sessions      SessionRepository
+	Conversations ConversationRepository
+	Changes       ChangeRepository
+	FetchStates   FetchStateRepository
+	AIUsage       AIUsageRepository
+	Reports       GameweekReportRepository
+
+	// Tx runs fn against a Store bound to one transaction; use WithTx.
+	Tx func(ctx context.Context, fn func(tx *Store) error) error
+}
+
+// WithTx runs fn in one transaction: whatever fn writes through tx is
+// committed together, or not at all when fn returns an error.
+func (s *Store) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	if s.Tx == nil {
+		return fn(s)
+	}
+	return s.Tx(ctx, fn)
+}
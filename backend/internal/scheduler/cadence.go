@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// FPL locks transfers 90 minutes before the first kickoff of a gameweek.
+	deadlineOffset = 90 * time.Minute
+	// Generous upper bound for a match incl. stoppage time and half-time.
+	matchLength = 2*time.Hour + 15*time.Minute
+)
+
+type fixtureCalendar struct {
+	live         bool
+	nextDeadline *time.Time
+	nextKickoff  *time.Time
+}
+
+// plan picks the next run time and the mode that explains it.
+func (s *Scheduler) plan(ctx context.Context, now time.Time) (time.Time, string) {
+	cal, err := s.loadCalendar(ctx, now)
+	if err != nil {
+		log.Printf("⚠️ Could not read fixtures for sync cadence: %v", err)
+		return now.Add(s.cfg.Interval), "normal"
+	}
+
+	if cal.live {
+		return now.Add(s.cfg.LiveInterval), "live"
+	}
+	if cal.nextDeadline != nil && cal.nextDeadline.Sub(now) <= s.cfg.DeadlineWindow {
+		return now.Add(s.cfg.DeadlineInterval), "deadline"
+	}
+
+	interval, mode := s.cfg.Interval, "normal"
+	if s.isNight(now) {
+		interval, mode = s.cfg.NightInterval, "night"
+	}
+	next := now.Add(interval)
+
+	// Don't sleep through the start of a deadline window or a kickoff.
+	if cal.nextDeadline != nil {
+		if windowStart := cal.nextDeadline.Add(-s.cfg.DeadlineWindow); windowStart.Before(next) {
+			next, mode = windowStart, "deadline"
+		}
+	}
+	if cal.nextKickoff != nil && cal.nextKickoff.Before(next) {
+		next, mode = *cal.nextKickoff, "live"
+	}
+	return next, mode
+}
+
+func (s *Scheduler) isNight(now time.Time) bool {
+	start, end := s.cfg.NightStartHour, s.cfg.NightEndHour
+	if start == end {
+		return false
+	}
+	h := now.In(s.loc).Hour()
+	if start < end {
+		return h >= start && h < end
+	}
+	return h >= start || h < end // wraps midnight, e.g. 23 -> 6
+}
+
+func (s *Scheduler) loadCalendar(ctx context.Context, now time.Time) (fixtureCalendar, error) {
+	var cal fixtureCalendar
+	if s.fixtures == nil {
+		return cal, nil
+	}
+
+	fixtures, err := s.fixtures.ListUnfinished(ctx)
+	if err != nil {
+		return cal, err
+	}
+
+	firstKickoff := map[int]time.Time{}
+	for _, f := range fixtures {
+		k := *f.KickoffTime
+		// Started-but-unfinished rows can be stale, so only trust them for a while.
+		if !k.After(now) && (now.Sub(k) < matchLength || (f.Started && now.Sub(k) < 2*matchLength)) {
+			cal.live = true
+		}
+		if k.After(now) && (cal.nextKickoff == nil || k.Before(*cal.nextKickoff)) {
+			k := k
+			cal.nextKickoff = &k
+		}
+		if f.Event != nil {
+			if cur, ok := firstKickoff[*f.Event]; !ok || k.Before(cur) {
+				firstKickoff[*f.Event] = k
+			}
+		}
+	}
+
+	for _, k := range firstKickoff {
+		d := k.Add(-deadlineOffset)
+		if d.After(now) && (cal.nextDeadline == nil || d.Before(*cal.nextDeadline)) {
+			cal.nextDeadline = &d
+		}
+	}
+	return cal, nil
+}
@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"bitbucket.org/Local/fpl-assistant/backend/internal/config"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/fplimporter"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/jobs"
+	"bitbucket.org/Local/fpl-assistant/backend/internal/repository"
+)
+
+// Status is what GET /v1/admin/sync-status reports.
+type Status struct {
+	Enabled      bool       `json:"enabled"`
+	Running      bool       `json:"running"`
+	Mode         string     `json:"mode,omitempty"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastSuccess  *time.Time `json:"last_success,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+type Scheduler struct {
+	cfg config.SyncConfig
+	loc *time.Location
+	run func(ctx context.Context) error
+
+	fixtures repository.FixtureRepository
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Start launches the background sync loop and returns immediately. The loop
+// stops when ctx is cancelled. Runs are submitted as import jobs on mgr so
+// they show up in /admin/jobs like manual ones.
+func Start(ctx context.Context, cfg *config.SyncConfig, im *fplimporter.Importer, mgr *jobs.Manager, fixtures repository.FixtureRepository) *Scheduler {
+	s := newScheduler(*cfg, fixtures, func(ctx context.Context) error {
+		job, err := im.SubmitImport(mgr, "schedule")
+		if err != nil {
+			return err
+		}
+		select {
+		case <-job.Done():
+			return job.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if !cfg.Enabled {
+		log.Println("⏸️ Background sync disabled")
+		return s
+	}
+	go s.loop(ctx)
+	return s
+}
+
+func newScheduler(cfg config.SyncConfig, fixtures repository.FixtureRepository, run func(ctx context.Context) error) *Scheduler {
+	applyDefaults(&cfg)
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Printf("⚠️ Unknown sync timezone %q, using UTC: %v", cfg.Timezone, err)
+		loc = time.UTC
+	}
+
+	return &Scheduler{
+		cfg: cfg,
+		loc: loc,
+		run: run,
+
+		fixtures: fixtures,
+		status:   Status{Enabled: cfg.Enabled},
+	}
+}
+
+func applyDefaults(cfg *config.SyncConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 6 * time.Hour
+	}
+	if cfg.DeadlineInterval <= 0 {
+		cfg.DeadlineInterval = 30 * time.Minute
+	}
+	if cfg.DeadlineWindow <= 0 {
+		cfg.DeadlineWindow = 24 * time.Hour
+	}
+	if cfg.LiveInterval <= 0 {
+		cfg.LiveInterval = 10 * time.Minute
+	}
+	if cfg.NightInterval <= 0 {
+		cfg.NightInterval = cfg.Interval
+	}
+	if cfg.Timezone == "" {
+		cfg.Timezone = "Europe/London"
+	}
+}
+
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	next, mode := time.Now(), "startup"
+	if !s.cfg.RunOnStart {
+		next, mode = s.plan(ctx, time.Now())
+	}
+
+	for {
+		s.setNext(next, mode)
+		log.Printf("🕒 Next FPL sync at %s (%s)", next.In(s.loc).Format(time.RFC3339), mode)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx)
+		next, mode = s.plan(ctx, time.Now())
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	started := time.Now()
+	s.mu.Lock()
+	s.status.Running = true
+	s.status.LastRun = &started
+	s.mu.Unlock()
+
+	err := s.run(ctx)
+
+	finished := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Running = false
+	s.status.LastDuration = finished.Sub(started).Round(time.Millisecond).String()
+
+	switch {
+	case errors.Is(err, jobs.ErrAlreadyRunning), errors.Is(err, fplimporter.ErrImportInProgress):
+		// Someone triggered a manual import; treat it as this run.
+		log.Println("⏭️ Scheduled sync skipped, an import is already running")
+		s.status.LastError = ""
+	case err != nil:
+		log.Printf("❌ Scheduled sync failed: %v", err)
+		s.status.LastError = err.Error()
+	default:
+		s.status.LastError = ""
+		s.status.LastSuccess = &finished
+	}
+}
+
+func (s *Scheduler) setNext(next time.Time, mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.NextRun = &next
+	s.status.Mode = mode
+}
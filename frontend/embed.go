@@ -0,0 +1,22 @@
+//go:build embedfrontend
+
+package frontend
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Vite output; run `yarn build` before `go build -tags embedfrontend`.
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Dist returns the embedded build, rooted at dist/.
+func Dist() (fs.FS, bool) {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, false
+	}
+	return sub, true
+}
@@ -0,0 +1,13 @@
+//go:build !embedfrontend
+
+// Package frontend exposes the built SPA to the Go server. Builds with the
+// embedfrontend tag carry dist/ inside the binary; other builds serve it from
+// disk (see server.static_dir).
+package frontend
+
+import "io/fs"
+
+// Dist reports that no build is embedded in this binary.
+func Dist() (fs.FS, bool) {
+	return nil, false
+}
@@ -0,0 +1,29 @@
+// Writes .br and .gz next to compressible files in dist/ so the Go server can
+// serve them precompressed. Runs after `vite build`.
+import { readdirSync, readFileSync, statSync, writeFileSync } from 'node:fs'
+import { join, extname } from 'node:path'
+import { brotliCompressSync, gzipSync, constants } from 'node:zlib'
+
+const root = new URL('../dist', import.meta.url).pathname
+const exts = new Set(['.html', '.js', '.css', '.svg', '.json', '.txt', '.map'])
+const minSize = 1024
+
+function walk(dir) {
+  for (const name of readdirSync(dir)) {
+    const file = join(dir, name)
+    if (statSync(file).isDirectory()) {
+      walk(file)
+      continue
+    }
+    if (!exts.has(extname(name))) continue
+    const data = readFileSync(file)
+    if (data.length < minSize) continue
+    writeFileSync(file + '.gz', gzipSync(data, { level: 9 }))
+    writeFileSync(
+      file + '.br',
+      brotliCompressSync(data, { params: { [constants.BROTLI_PARAM_QUALITY]: 11 } }),
+    )
+  }
+}
+
+walk(root)
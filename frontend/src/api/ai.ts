@@ -0,0 +1,57 @@
+// Shapes mirror AskRequest / AskResponse in backend/internal/network/v1/dto.go
+export type AskSquad = {
+    starters: number[];
+    bench: number[];
+    captain_id?: number;
+    vice_id?: number;
+    budget_left: number;
+};
+export type AskRequest = { message: string; squad?: AskSquad };
+export type AskResponse = { reply: string; model: string };
+
+// askStream posts to /v1/ask-ai/stream and calls onDelta as text arrives.
+// Aborting the signal closes the connection, which cancels the model call.
+export async function askStream(
+    body: AskRequest,
+    onDelta: (text: string) => void,
+    signal?: AbortSignal,
+): Promise<AskResponse> {
+    const res = await fetch('/v1/ask-ai/stream', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', Accept: 'text/event-stream' },
+        body: JSON.stringify(body),
+        signal,
+    });
+    if (!res.ok || !res.body) {
+        // errors before the first delta are normal {error: {code, message}} JSON
+        const j = await res.json().catch(() => null);
+        throw new Error(j?.error?.message || `HTTP ${res.status}`);
+    }
+
+    const reader = res.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buf = '';
+    let done: AskResponse | null = null;
+    for (;;) {
+        const { value, done: eof } = await reader.read();
+        if (eof) break;
+        buf += value;
+        // events are separated by a blank line
+        let sep: number;
+        while ((sep = buf.indexOf('\n\n')) >= 0) {
+            const raw = buf.slice(0, sep);
+            buf = buf.slice(sep + 2);
+            let event = 'message';
+            let data = '';
+            for (const line of raw.split('\n')) {
+                if (line.startsWith('event:')) event = line.slice(6).trim();
+                else if (line.startsWith('data:')) data += line.slice(5).trim();
+            }
+            const j = data ? JSON.parse(data) : null;
+            if (event === 'delta') onDelta(j?.text ?? '');
+            else if (event === 'done') done = j;
+            else if (event === 'error') throw new Error(j?.message || 'stream failed');
+        }
+    }
+    if (!done) throw new Error('connection closed before the reply finished');
+    return done;
+}
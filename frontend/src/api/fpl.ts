@@ -1,60 +1,96 @@
 import type { Team, Player } from '../types/fpl';
-import { mapPos, normalizePrice, toNum } from '../utils/fpl';
+import { mapPos } from '../utils/fpl';
 import type { Fixture } from '../types/fpl';
 
 
+// Shapes below mirror the v1 DTOs (backend/internal/network/v1/dto.go)
+type TeamDTO = { id: number; name: string; short_name: string; code: string };
+type FixtureDTO = Fixture & { team_h_short_name: string; team_a_short_name: string };
+type PlayerDTO = {
+    id: number;
+    first_name: string;
+    last_name: string;
+    web_name: string;
+    team_id: number;
+    team_short_name: string;
+    position: string;
+    price: number;
+    form: number;
+    selected_by_percent: number;
+    event_points: number;
+};
+
 export async function loadTeams(): Promise<Record<number, Team>> {
     const r = await fetch('/v1/teams');
-    const rows = await r.json();
+    const rows: TeamDTO[] = await r.json();
     const map: Record<number, Team> = {};
-    (rows || []).forEach((t: any) => {
-        const id = Number(t.id ?? t.ID);
-        map[id] = {
-            id,
-            name: String(t.name ?? t.Name ?? ''),
-            shortName: String(t.shortName ?? t.ShortName ?? ''),
-            code: String(t.code ?? t.Code ?? ''),
-        };
+    (rows || []).forEach(t => {
+        map[t.id] = { id: t.id, name: t.name, shortName: t.short_name, code: t.code };
     });
     return map;
 }
 export async function loadFixtures(): Promise<Fixture[]> {
     const res = await fetch('/v1/fixtures');
     if (!res.ok) return [];
-    const rows: any[] = await res.json();
-    return rows.map((f: any): Fixture => ({
-        id: f.ID ?? f.id,
-        event: f.Event ?? f.event ?? null,
-        kickoff_time: (f.KickoffTime ?? f.kickoff_time ?? null),
-        started: !!(f.Started ?? f.started),
-        finished: !!(f.Finished ?? f.finished),
-        team_a: f.TeamAID ?? f.team_a,
-        team_h: f.TeamHID ?? f.team_h,
-        team_a_difficulty: (f.TeamADifficulty ?? f.team_a_difficulty) as 1 | 2 | 3 | 4 | 5,
-        team_h_difficulty: (f.TeamHDifficulty ?? f.team_h_difficulty) as 1 | 2 | 3 | 4 | 5,
+    const rows: FixtureDTO[] = await res.json();
+    return rows.map((f): Fixture => ({
+        id: f.id,
+        event: f.event,
+        kickoff_time: f.kickoff_time,
+        started: f.started,
+        finished: f.finished,
+        team_a: f.team_a,
+        team_h: f.team_h,
+        team_a_difficulty: f.team_a_difficulty,
+        team_h_difficulty: f.team_h_difficulty,
     }));
 }
 export async function loadPlayers(): Promise<Player[]> {
     const r = await fetch('/v1/players');
-    const rows = await r.json();
-    const list: Player[] = (rows || []).map((p: any) => ({
-        id: Number(p.id ?? p.ID),
-        firstName: String(p.firstName ?? p.FirstName ?? ''),
-        lastName: String(p.lastName ?? p.LastName ?? ''),
-        webName: String(p.webName ?? p.WebName ?? p.lastName ?? p.LastName ?? ''),
-        position: mapPos(p.position ?? p.Position),
-        teamId: Number(p.teamId ?? p.TeamID ?? 0),
-        price: normalizePrice(p.currentPrice ?? p.CurrentPrice ?? p.price),
-        expectedPoints: toNum(p.expectedPoints) ?? toNum(p.predictedPoints),
-        form: Number(p.Form ?? p.form ?? p.ValueForm ?? 0),
-        selectedByPercent: toNum(p.SelectedByPercent ?? p.selectedByPercent),
-        eventPoints: Number(p.event_points ?? p.eventPoints ?? 0),
+    const rows: PlayerDTO[] = await r.json();
+    return (rows || []).map((p): Player => ({
+        id: p.id,
+        firstName: p.first_name,
+        lastName: p.last_name,
+        webName: p.web_name || p.last_name,
+        position: mapPos(p.position),
+        teamId: p.team_id,
+        price: p.price,
+        form: p.form,
+        selectedByPercent: p.selected_by_percent,
+        eventPoints: p.event_points,
     }));
-    return list;
 }
 
-export async function importFPL(fullUrl = 'http://localhost:8080/v1/admin/import-fpl') {
-    let res = await fetch(fullUrl, { method: 'POST' });
-    if (!res.ok) res = await fetch(fullUrl); // GET fallback
-    if (!res.ok) throw new Error(`Import failed: HTTP ${res.status}`);
+// /v1/admin needs an API key; VITE_ADMIN_API_KEY supplies one in dev only.
+// The DEV check keeps it out of production bundles.
+const adminKey: string | undefined = import.meta.env.DEV ? import.meta.env.VITE_ADMIN_API_KEY : undefined;
+
+// Without a key the import endpoint only ever answers 401, so the UI hides it.
+export const canImport = Boolean(adminKey);
+
+function adminHeaders(): HeadersInit {
+    return adminKey ? { 'X-API-Key': adminKey } : {};
+}
+
+export async function importFPL(fullUrl = '/v1/admin/import-fpl') {
+    const res = await fetch(fullUrl, { method: 'POST', headers: adminHeaders() });
+    // 202 = queued, 409 = already running; both hand back a job to follow
+    const body = await res.json().catch(() => null);
+    if (!res.ok && res.status !== 409) throw new Error(body?.error?.message || `Import failed: HTTP ${res.status}`);
+    const job_id = body?.job_id ?? body?.error?.details?.job_id;
+    await waitForJob(fullUrl.replace(/import-fpl$/, `jobs/${job_id}`));
+}
+
+async function waitForJob(jobUrl: string, intervalMs = 1000) {
+    for (; ;) {
+        const res = await fetch(jobUrl, { headers: adminHeaders() });
+        if (!res.ok) throw new Error(`Import status failed: HTTP ${res.status}`);
+        const job = await res.json();
+        if (job.state === 'succeeded') return;
+        if (job.state === 'failed' || job.state === 'cancelled') {
+            throw new Error(`Import ${job.state}: ${(job.errors || []).join('; ')}`);
+        }
+        await new Promise(r => setTimeout(r, intervalMs));
+    }
 }
@@ -1,5 +1,6 @@
-import React, { useRef, useState } from 'react';
+import React, { useEffect, useRef, useState } from 'react';
 import type { Player, Team } from '../types/fpl';
+import { askStream } from '../api/ai';
 
 type Msg = { role: 'user' | 'assistant'; text: string };
 
@@ -7,50 +8,64 @@ type Props = {
     open?: boolean;
     onClose?: () => void;
     teams: Record<number, Team>;   // kept for future use
-    starters: Player[];            // kept for future use
-    bench: Player[];               // kept for future use
-    budgetLeft: number;            // kept for future use
+    starters: Player[];
+    bench: Player[];
+    captainId?: number | null;
+    viceId?: number | null;
+    budgetLeft: number;
 };
 
-export default function ChatBox({ open = true, onClose }: Props) {
+export default function ChatBox({ open = true, onClose, starters, bench, captainId, viceId, budgetLeft }: Props) {
     const [messages, setMessages] = useState<Msg[]>([
         { role: 'assistant', text: 'Hi! Ask me anything about your squad, captain picks, or transfers.' }
     ]);
     const [input, setInput] = useState('');
     const [busy, setBusy] = useState(false);
     const scrollerRef = useRef<HTMLDivElement>(null);
+    const abortRef = useRef<AbortController | null>(null);
+
+    // close the stream (and the model call) if the chat goes away mid-answer
+    useEffect(() => () => abortRef.current?.abort(), []);
+
+    const scrollDown = () => requestAnimationFrame(() => {
+        scrollerRef.current?.scrollTo({ top: scrollerRef.current.scrollHeight, behavior: 'smooth' });
+    });
+
+    // replaces the text of the last (assistant) bubble
+    const setLast = (f: (text: string) => string) =>
+        setMessages(m => [...m.slice(0, -1), { role: 'assistant', text: f(m[m.length - 1].text) }]);
 
     const send = async () => {
         const text = input.trim();
         if (!text || busy) return;
-        setMessages(m => [...m, { role: 'user', text }]);
+        setMessages(m => [...m, { role: 'user', text }, { role: 'assistant', text: '' }]);
         setInput('');
         setBusy(true);
 
+        const ctrl = new AbortController();
+        abortRef.current = ctrl;
         try {
-            const res = await fetch('/v1/ask-ai', {
-                method: 'POST',
-                headers: { 'Content-Type': 'application/json' },
-                body: JSON.stringify({ message: text }),
-            });
-
-            const raw = await res.text();
-            if (!res.ok) {
-                let msg = raw;
-                try { const j = JSON.parse(raw); msg = j.error || j.message || raw; } catch { }
-                throw new Error(`HTTP ${res.status}: ${msg}`);
-            }
-            let reply = '(no reply)';
-            try { reply = (JSON.parse(raw).reply) ?? reply; } catch { reply = raw || reply; }
-
-            setMessages(m => [...m, { role: 'assistant', text: reply }]);
+            // the server looks up stats/fixtures itself, ids are enough
+            const res = await askStream({
+                message: text,
+                squad: {
+                    starters: starters.map(p => p.id),
+                    bench: bench.map(p => p.id),
+                    captain_id: captainId ?? undefined,
+                    vice_id: viceId ?? undefined,
+                    budget_left: budgetLeft,
+                },
+            }, delta => {
+                setLast(t => t + delta);
+                scrollDown();
+            }, ctrl.signal);
+            setLast(() => res.reply || '(no reply)');
         } catch (err: any) {
-            setMessages(m => [...m, { role: 'assistant', text: `Error: ${err?.message || String(err)}` }]);
+            if (ctrl.signal.aborted) return;
+            setLast(t => (t ? t + '\n\n' : '') + `Error: ${err?.message || String(err)}`);
         } finally {
             setBusy(false);
-            requestAnimationFrame(() => {
-                scrollerRef.current?.scrollTo({ top: scrollerRef.current.scrollHeight, behavior: 'smooth' });
-            });
+            scrollDown();
         }
     };
 
@@ -70,7 +85,7 @@ export default function ChatBox({ open = true, onClose }: Props) {
 
             <div className="chatbox-body" ref={scrollerRef}>
                 {messages.map((m, i) => (
-                    <div key={i} className={`bubble ${m.role}`}>{m.text}</div>
+                    <div key={i} className={`bubble ${m.role}`}>{m.text || '…'}</div>
                 ))}
             </div>
 
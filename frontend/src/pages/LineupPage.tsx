@@ -2,7 +2,7 @@ import { useCallback, useEffect, useMemo, useRef, useState } from 'react';
 import './LineupPage.css';
 import type { ActiveSlot, Formation, Player, Team, Fixture } from '../types/fpl';
 import { ALLOWED_FORMATIONS, scoreOf } from '../utils/fpl';
-import { importFPL, loadPlayers, loadTeams } from '../api/fpl';
+import { canImport, importFPL, loadPlayers, loadTeams } from '../api/fpl';
 import { isCompatibleDrag, useLineup } from '../hooks/useLineup';
 import ChatBox from '../components/ChatBox';
 import Pitch from '../components/Pitch';
@@ -121,7 +121,7 @@ export default function LineupPage() {
     const runUpdate = useCallback(async () => {
         try {
             setIsUpdating(true); setUpdateStatus('idle');
-            await importFPL();          // http://localhost:8080/v1/admin/import-fpl
+            await importFPL();          // /v1/admin/import-fpl
             await refresh();
             setUpdateStatus('ok');
         } catch {
@@ -170,9 +170,11 @@ export default function LineupPage() {
                         {ALLOWED_FORMATIONS.map(f => <option key={f} value={f}>{f}</option>)}
                     </select>
 
-                    <button className="btn" onClick={runUpdate} disabled={isUpdating}>
-                        {isUpdating ? 'Updating…' : 'Update'}
-                    </button>
+                    {canImport && (
+                        <button className="btn" onClick={runUpdate} disabled={isUpdating}>
+                            {isUpdating ? 'Updating…' : 'Update'}
+                        </button>
+                    )}
                     {updateStatus === 'ok' && <span className="chip" title="Latest data loaded">Updated</span>}
                     {updateStatus === 'error' && <span className="chip" title="Import failed">Update failed</span>}
                 </div>
@@ -206,6 +208,8 @@ export default function LineupPage() {
                 teams={teams}
                 starters={L.starters}
                 bench={L.bench}
+                captainId={L.captainId}
+                viceId={L.viceId}
                 budgetLeft={budgetLeft}
             />
         </div>
@@ -0,0 +1,10 @@
+/// <reference types="vite/client" />
+
+interface ImportMetaEnv {
+    // Dev only: adminHeaders ignores it outside `vite dev`
+    readonly VITE_ADMIN_API_KEY?: string;
+}
+
+interface ImportMeta {
+    readonly env: ImportMetaEnv;
+}